@@ -1,6 +1,7 @@
 package interview_accountapi
 
 import (
+	"context"
 	"fmt"
 	"github.com/google/uuid"
 	"os"
@@ -10,9 +11,10 @@ import (
 func Test_Integration_Fetch_IdIsNotUuid(t *testing.T) {
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(getBaseUrl())
-	account, httpErr := client.Fetch("blah")
+	account, httpErr := client.Fetch(context.Background(), "blah")
 	assertHttpError(t, httpErr, &HTTPError{
 		Message: "id must be a valid uuid",
+		Kind:    KindValidation,
 	})
 	assertAccountData(t, account, nil)
 }
@@ -21,11 +23,14 @@ func Test_Integration_Fetch_404(t *testing.T) {
 	id, _ := uuid.NewUUID()
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(getBaseUrl())
-	account, httpErr := client.Fetch(id.String())
+	account, httpErr := client.Fetch(context.Background(), id.String())
 	expectedPayload := []byte(`{"error_message":"record ` + id.String() + ` does not exist"}`)
 	assertHttpError(t, httpErr, &HTTPError{
 		StatusCode:      404,
+		Status:          "404 Not Found",
 		Message:         "Unexpected response code returned for Get operation, expected 200, got 404",
+		Kind:            KindNotFound,
+		ServerMessage:   "record " + id.String() + " does not exist",
 		ResponsePayload: &expectedPayload,
 	})
 	assertAccountData(t, account, nil)
@@ -63,14 +68,17 @@ func Test_Integration_CreateBadRequest(t *testing.T) {
 
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(getBaseUrl())
-	createRespAccount, httpErr := client.Create(requestAccount)
+	createRespAccount, httpErr := client.Create(context.Background(), requestAccount)
 
 	responsePayload := []byte(`{"error_message":"validation failure list:\nvalidation failure list:\nvalidation failure list:\naccount_classification in body should be one of [Personal Business]"}`)
 
 	assertHttpError(t, httpErr, &HTTPError{
 		StatusCode:      400,
+		Status:          "400 Bad Request",
 		ResponsePayload: &responsePayload,
 		Message:         "Unexpected response code returned for Post operation, expected 201, got 400",
+		Kind:            KindValidation,
+		ServerMessage:   "validation failure list:\nvalidation failure list:\nvalidation failure list:\naccount_classification in body should be one of [Personal Business]",
 	})
 	assertAccountData(t, createRespAccount, nil)
 }
@@ -80,19 +88,22 @@ func Test_Integration_CreateDuplicateConstraint(t *testing.T) {
 
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(getBaseUrl())
-	createRespAccount, httpErr := client.Create(requestAccount)
+	createRespAccount, httpErr := client.Create(context.Background(), requestAccount)
 
 	assertHttpError(t, httpErr, nil)
 	assertAccountData(t, createRespAccount, requestAccount)
 
-	createRespAccount, httpErr = client.Create(requestAccount)
+	createRespAccount, httpErr = client.Create(context.Background(), requestAccount)
 
 	responsePayload := []byte(`{"error_message":"Account cannot be created as it violates a duplicate constraint"}`)
 
 	assertHttpError(t, httpErr, &HTTPError{
 		Cause:           nil,
 		Message:         "Unexpected response code returned for Post operation, expected 201, got 409",
+		Kind:            KindConflict,
+		ServerMessage:   "Account cannot be created as it violates a duplicate constraint",
 		StatusCode:      409,
+		Status:          "409 Conflict",
 		ResponsePayload: &responsePayload,
 	})
 	assertAccountData(t, createRespAccount, nil)
@@ -103,37 +114,42 @@ func Test_Integration_CreateFetchDelete(t *testing.T) {
 
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(getBaseUrl())
-	createRespAccount, httpErr := client.Create(requestAccount)
+	createRespAccount, httpErr := client.Create(context.Background(), requestAccount)
 
 	assertHttpError(t, httpErr, nil)
 	assertAccountData(t, createRespAccount, requestAccount)
 
-	fetchRespAccount, httpErr := client.Fetch(createRespAccount.ID)
+	fetchRespAccount, httpErr := client.Fetch(context.Background(), createRespAccount.ID)
 	assertHttpError(t, httpErr, nil)
 	assertAccountData(t, fetchRespAccount, requestAccount)
 
-	httpErr = client.Delete(fetchRespAccount.ID, *fetchRespAccount.Version)
+	httpErr = client.Delete(context.Background(), fetchRespAccount.ID, *fetchRespAccount.Version)
 	assertHttpError(t, httpErr, nil)
 
-	httpErr = client.Delete(fetchRespAccount.ID, *fetchRespAccount.Version)
+	httpErr = client.Delete(context.Background(), fetchRespAccount.ID, *fetchRespAccount.Version)
 
 	emptyByteSlice := make([]byte, 0)
 
 	assertHttpError(t, httpErr, &HTTPError{
 		Cause:           nil,
 		Message:         "Unexpected response code returned for Delete operation, expected 204, got 404",
+		Kind:            KindNotFound,
 		StatusCode:      404,
+		Status:          "404 Not Found",
 		ResponsePayload: &emptyByteSlice,
 	})
 
 	expectedPayload := []byte(`{"error_message":"record ` + requestAccount.ID + ` does not exist"}`)
 
-	fetchRespAccount, httpErr = client.Fetch(createRespAccount.ID)
+	fetchRespAccount, httpErr = client.Fetch(context.Background(), createRespAccount.ID)
 	assertHttpError(t, httpErr, &HTTPError{
 		Cause:           nil,
 		ResponsePayload: &expectedPayload,
 		StatusCode:      404,
+		Status:          "404 Not Found",
 		Message:         "Unexpected response code returned for Get operation, expected 200, got 404",
+		Kind:            KindNotFound,
+		ServerMessage:   "record " + requestAccount.ID + " does not exist",
 	})
 	assertAccountData(t, fetchRespAccount, nil)
 }
@@ -146,75 +162,70 @@ func Test_Integration_Delete(t *testing.T) {
 
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(getBaseUrl())
-	httpErr := client.Delete(id, *version) // there is nothing to delete, 404 is the correct answer
+	httpErr := client.Delete(context.Background(), id, *version) // there is nothing to delete, 404 is the correct answer
 
 	emptyByteSlice := make([]byte, 0)
 
 	assertHttpError(t, httpErr, &HTTPError{
 		Cause:           nil,
 		StatusCode:      404,
+		Status:          "404 Not Found",
 		Message:         "Unexpected response code returned for Delete operation, expected 204, got 404",
+		Kind:            KindNotFound,
 		ResponsePayload: &emptyByteSlice,
 	})
 
-	client.Create(requestAccount)
+	client.Create(context.Background(), requestAccount)
 
 	wrongVersion := int64(1)
 	responsePayload := []byte(`{"error_message":"invalid version"}`)
 
-	httpErr = client.Delete(id, wrongVersion)
+	httpErr = client.Delete(context.Background(), id, wrongVersion)
 
 	assertHttpError(t, httpErr, &HTTPError{
 		Cause:           nil,
 		StatusCode:      409,
+		Status:          "409 Conflict",
 		Message:         "Unexpected response code returned for Delete operation, expected 204, got 409",
+		Kind:            KindConflict,
+		ServerMessage:   "invalid version",
 		ResponsePayload: &responsePayload,
 	})
 
-	httpErr = client.Delete(id, *version)
+	httpErr = client.Delete(context.Background(), id, *version)
 	assertHttpError(t, httpErr, nil)
 
-	httpErr = client.Delete(id, *version) // there is nothing to delete, 404 is the correct answer
+	httpErr = client.Delete(context.Background(), id, *version) // there is nothing to delete, 404 is the correct answer
 
 	assertHttpError(t, httpErr, &HTTPError{
 		Cause:           nil,
 		StatusCode:      404,
+		Status:          "404 Not Found",
 		Message:         "Unexpected response code returned for Delete operation, expected 204, got 404",
+		Kind:            KindNotFound,
 		ResponsePayload: &emptyByteSlice,
 	})
 }
 
 func getValidAccountData() *AccountData {
-	accountMatchingOptOut := false
-	country := "CA"
-	jointAccount := true
-	status := "pending"
-	switched := true
-	version := int64(0)
-	id := uuid.NewString()
-	requestAccount := &AccountData{
-		Attributes: &AccountAttributes{
-			AccountMatchingOptOut:   &accountMatchingOptOut,
-			AccountNumber:           "A1234567",
-			AlternativeNames:        []string{"x", "y", "z"},
-			BankID:                  "GBDSC",
-			BankIDCode:              "BIDC",
-			BaseCurrency:            "CAD",
-			Bic:                     "AAAAAABB",
-			Country:                 &country,
-			Iban:                    "II00",
-			JointAccount:            &jointAccount,
-			Name:                    []string{"a", "b", "c"},
-			SecondaryIdentification: "Driver's License",
-			Status:                  &status,
-			Switched:                &switched,
-		},
-		ID:             id,
-		OrganisationID: uuid.NewString(),
-		Type:           "accounts",
-		Version:        &version,
-	}
-	return requestAccount
+	return NewAccountDataBuilder().
+		WithOrganisationID(uuid.NewString()).
+		WithVersion(0).
+		WithAccountMatchingOptOut(false).
+		WithAccountNumber("A1234567").
+		WithAlternativeNames("x", "y", "z").
+		WithBankID("GBDSC").
+		WithBankIDCode("BIDC").
+		WithBaseCurrency("CAD").
+		WithBic("AAAAAABB").
+		WithCountry("CA").
+		WithIban("II00").
+		WithJointAccount(true).
+		WithName("a", "b", "c").
+		WithSecondaryIdentification("Driver's License").
+		WithStatus("pending").
+		WithSwitched(true).
+		Build()
 }
 
 func getBaseUrl() string {
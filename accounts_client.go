@@ -2,16 +2,27 @@ package interview_accountapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"golang.org/x/time/rate"
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// HttpAccountsClient is a client of the accounts API. It is safe for concurrent use by multiple goroutines:
+// its configuration is fixed once built, and what it shares between calls, i.e. the http.Client, the rate limiter
+// and the hooks given as options, is safe for concurrent use too, or has to be in case of hooks.
+// A single client is meant to be shared rather than one being built per call, so that connections get reused.
 type HttpAccountsClient interface {
 	// Fetch returns a pointer to an object of type AccountData based on provided identifier.
 	// If there is any internal client error during request placement and response analysis,
@@ -21,7 +32,46 @@ type HttpAccountsClient interface {
 	// the AccountData pointer will be set to nil in this case.
 	// The return values are mutually exclusive, you either get a valid AccountData object
 	// if operation succeeded or HTTPError if there was any error.
-	Fetch(id string) (*AccountData, *HTTPError)
+	// The context governs cancellation and deadline of the underlying Http request.
+	Fetch(ctx context.Context, id string) (*AccountData, *HTTPError)
+
+	// FetchWithStaleness behaves exactly like Fetch, additionally telling whether the account is a stale one
+	// the client fell back on as the server failed, see WithGracefulDegradation.
+	FetchWithStaleness(ctx context.Context, id string) (*AccountData, bool, *HTTPError)
+
+	// FetchWithResponse behaves exactly like Fetch, additionally returning a copy of the response headers
+	// (e.g. Date, X-Request-Id, ETag, X-Schema-Version) when the operation succeeded.
+	// The headers are nil whenever an HTTPError is returned.
+	FetchWithResponse(ctx context.Context, id string) (*AccountData, http.Header, *HTTPError)
+
+	// FetchWithParams behaves like Fetch, additionally passing params in the query string of the request,
+	// e.g. a sparse fieldset for API variants supporting one. The account is never served from the cache,
+	// see WithFetchCache, as it may not be complete.
+	FetchWithParams(ctx context.Context, id string, params url.Values) (*AccountData, *HTTPError)
+
+	// FetchHead issues a HEAD request for the account identified by id, returning the response headers
+	// on status code 200 without transferring the account itself.
+	// Any other status code is reported as an HTTPError, the headers are nil in this case.
+	FetchHead(ctx context.Context, id string) (http.Header, *HTTPError)
+
+	// FetchWithETag behaves like Fetch, additionally returning the ETag the server tagged the account with,
+	// empty if there is none, for conditional updates and deletes with UpdateIfMatch and DeleteIfMatch.
+	FetchWithETag(ctx context.Context, id string) (*AccountData, string, *HTTPError)
+
+	// Exists tells whether the account identified by id is present, i.e. whether a HEAD request for it yields
+	// status code 200 rather than 404. Any other outcome is reported as an HTTPError, along with false.
+	Exists(ctx context.Context, id string) (bool, *HTTPError)
+
+	// FetchVersion returns the version the account identified by id currently has, e.g. to pass it to Update
+	// or Delete. The account is fetched from the server even with WithFetchCache, a cached version could be stale.
+	// An account fetched without a version is reported as an HTTPError of kind KindServer.
+	FetchVersion(ctx context.Context, id string) (int64, *HTTPError)
+
+	// FetchMany fetches every account of ids with an individual Fetch, running up to 4 of them at a time
+	// unless configured otherwise with WithConcurrency. Ids that are not valid uuids are rejected without
+	// a request being sent, and ids given more than once are fetched once.
+	// Every id ends up in exactly one of the returned maps, along with either its account or its HTTPError.
+	FetchMany(ctx context.Context, ids []string) (map[string]*AccountData, map[string]*HTTPError)
 
 	// Create returns a pointer to a newly created object of type AccountData.
 	// If there is any internal client error during request placement and response analysis,
@@ -29,55 +79,1007 @@ type HttpAccountsClient interface {
 	// If the response returned is not identified as a successful operation (status code 201),
 	// the pointer to instantiated HTTPError object will be returned,
 	// the AccountData pointer will be set to nil in this case.
+	// If the server accepted the account for asynchronous processing (status code 202),
+	// the Location header is polled until the account is available, see awaitCompletion for details,
+	// unless 202 is accepted as success with WithSuccessStatus, in which case the response body is the account.
 	// The return values are mutually exclusive, you either get a valid AccountData object
 	// if operation succeeded or HTTPError if there was any error.
-	Create(a *AccountData) (*AccountData, *HTTPError)
+	// The context governs cancellation and deadline of the underlying Http request.
+	Create(ctx context.Context, a *AccountData) (*AccountData, *HTTPError)
+
+	// BuildCreateRequest returns the request Create would send for a, headers and body included, without sending it,
+	// e.g. to inspect the wire format or to sign the request. The account is checked and serialized as by Create,
+	// failures doing so are reported as by Create.
+	BuildCreateRequest(ctx context.Context, a *AccountData) (*http.Request, *HTTPError)
+
+	// CreateWithIdempotencyKey behaves like Create, additionally sending key in the Idempotency-Key header
+	// so that the server can tell a repeated create, e.g. one retried by the caller after a timeout,
+	// from an attempt to create a duplicate account. The key must not be empty.
+	CreateWithIdempotencyKey(ctx context.Context, key string, a *AccountData) (*AccountData, *HTTPError)
+
+	// CreateBatch creates every account of the batch with an individual Create, running up to 4 of them at a time
+	// unless configured otherwise with WithConcurrency. A failing create doesn't stop the others.
+	// Both returned slices are aligned with accounts by index: for every account either the created account
+	// or the HTTPError is set, the other one being nil.
+	CreateBatch(ctx context.Context, accounts []*AccountData) ([]*AccountData, []*HTTPError)
+
+	// CreateFromReader behaves like Create, except the request body is streamed from r as is,
+	// sparing the deserialization and serialization of a payload that is already at hand.
+	// The reader must yield a json envelope, i.e. {"data": {...}}, it is not validated client side.
+	// Since a stream can only be sent once, the request is never retried.
+	CreateFromReader(ctx context.Context, r io.Reader) (*AccountData, *HTTPError)
+
+	// Update applies the attributes of a to the existing account identified by id, returning the updated account.
+	// The version must match the current version of the account on the server, it is sent in the request body
+	// and a mismatch is reported by the server with status code 409.
+	// If the response returned is not identified as a successful operation (status code 200),
+	// the pointer to instantiated HTTPError object will be returned,
+	// the AccountData pointer will be set to nil in this case.
+	// The return values are mutually exclusive, you either get a valid AccountData object
+	// if operation succeeded or HTTPError if there was any error.
+	// The context governs cancellation and deadline of the underlying Http request.
+	Update(ctx context.Context, id string, version int64, a *AccountData) (*AccountData, *HTTPError)
+
+	// UpdateIfMatch behaves like Update, except that the update is conditional on the account still carrying
+	// the given ETag, as returned by FetchWithETag. The server rejecting the precondition (status code 412)
+	// is reported as a KindConflict HTTPError, as the account was modified since it was fetched.
+	UpdateIfMatch(ctx context.Context, id string, version int64, a *AccountData, ifMatch string) (*AccountData, *HTTPError)
+
+	// BuildUpdateRequest returns the request Update would send, see BuildCreateRequest. The no-op check of
+	// WithSkipNoopUpdates is left out, it takes a request of its own.
+	BuildUpdateRequest(ctx context.Context, id string, version int64, a *AccountData) (*http.Request, *HTTPError)
+
+	// UpdateWithRetry fetches the account identified by id, hands it to mutate and updates the account with
+	// whatever mutate returns, at the version fetched. Losing a race against a concurrent change (status code 409)
+	// starts over with a fresh fetch, up to maxAttempts attempts in total, after which the last conflict is returned.
+	// mutate may thus be called more than once, and always gets the latest account to work on, never a cached one.
+	UpdateWithRetry(ctx context.Context, id string, mutate func(*AccountData) *AccountData, maxAttempts int) (*AccountData, *HTTPError)
 
 	// Delete returns a pointer to a HTTPError struct if there was any internal client error
 	// during request placement and response analysis.
 	// If the response returned is not identified as a successful operation (status code 204),
 	// the pointer to instantiated HTTPError object will be returned.
-	Delete(id string, version int64) *HTTPError
+	// With WithDeleteVerification, a 204 is followed by a fetch of the account, which must yield a 404.
+	// The context governs cancellation and deadline of the underlying Http request.
+	Delete(ctx context.Context, id string, version int64) *HTTPError
+
+	// DeleteIfExists behaves like Delete, except that an account which is already gone (status code 404)
+	// counts as deleted and yields nil, which is what cleanup code usually wants.
+	// Version conflicts (status code 409) and any other failure are still reported.
+	DeleteIfExists(ctx context.Context, id string, version int64) *HTTPError
+
+	// DeleteIfMatch behaves like Delete, except that the delete is conditional on the account still carrying
+	// the given ETag, see UpdateIfMatch.
+	DeleteIfMatch(ctx context.Context, id string, version int64, ifMatch string) *HTTPError
+
+	// BuildDeleteRequest returns the request Delete would send, see BuildCreateRequest.
+	BuildDeleteRequest(ctx context.Context, id string, version int64) (*http.Request, *HTTPError)
+
+	// FetchThenDelete deletes the account identified by id at whatever version it currently has,
+	// sparing the caller the Fetch needed to learn it. Errors of the fetch, including 404, are returned unchanged.
+	// Should the account change in between the two requests, the delete fails with a version conflict.
+	FetchThenDelete(ctx context.Context, id string) *HTTPError
+
+	// List returns a single page of accounts along with the pagination links returned by the server.
+	// Page numbers start at 0, the page size must be between 1 and 100.
+	// If the response returned is not identified as a successful operation (status code 200),
+	// the pointer to instantiated HTTPError object will be returned and the accounts slice will be nil.
+	// A page past the last one is not an error, it simply yields an empty slice.
+	// Use Links.HasNext to find out whether there are more pages to fetch.
+	// With WithAutoPaginate, a page number and size both 0 return every account instead, as ListAll does,
+	// along with nil links; following more than 1000 pages is given up on with an HTTPError.
+	// The context governs cancellation and deadline of the underlying Http request.
+	List(ctx context.Context, pageNumber, pageSize int) ([]*AccountData, *Links, *HTTPError)
+
+	// ListFiltered behaves like List, narrowing the accounts down to the ones matching all the given filters,
+	// each of them sent as a filter[key]=value query parameter. The supported keys are account_number, bank_id,
+	// bank_id_code, country, customer_id and iban, any other key is rejected client side.
+	ListFiltered(ctx context.Context, filters map[string]string, pageNumber, pageSize int) ([]*AccountData, *Links, *HTTPError)
+
+	// ListAll returns every account by starting at the first page and following the links.next
+	// url advertised by the server until there is none.
+	// If the server advertises a next link that has already been followed, pagination is aborted
+	// with an HTTPError, as is the case with any failure fetching one of the pages;
+	// accounts from the pages fetched so far are discarded in this case.
+	// The context governs cancellation and deadline of all the underlying Http requests.
+	ListAll(ctx context.Context) ([]*AccountData, *HTTPError)
+
+	// HealthCheck tells whether the service is ready to take traffic by issuing a GET to its health path,
+	// "/v1/health" unless configured otherwise with WithHealthPath. Only status code 200 counts as healthy,
+	// any other response is reported as an HTTPError carrying the status code.
+	HealthCheck(ctx context.Context) *HTTPError
+
+	// Ping tells whether the server can be reached at all, for deployments exposing no health endpoint.
+	// It asks for a single account, and any response, 4xx and 5xx included, counts as the server being reachable.
+	// Only failing to get a response, e.g. the connection being refused, is reported as an HTTPError.
+	Ping(ctx context.Context) *HTTPError
+
+	// Warmup primes the connection pool by issuing n lightweight requests to the service host in parallel,
+	// so that the following calls can reuse already established connections.
+	// Any Http response counts as a successful warmup, only failures to reach the host are reported.
+	// Keep in mind that the transport only keeps a limited number of idle connections per host.
+	Warmup(ctx context.Context, n int) *HTTPError
+
+	// DoRaw is an escape hatch for endpoints the typed operations don't cover: it sends a request with the given
+	// method to subPath of the base url, e.g. "/v1/organisation/parties", carrying body, if not nil, and headers
+	// on top of the ones configured on the client. Requests with an idempotent method, i.e. GET, HEAD, PUT, DELETE
+	// and OPTIONS, are retried as configured with WithRetry, any other request is sent only once.
+	// The response is returned along with its body, already read in full and closed, whatever its status code;
+	// only failing to get a response or to read it is reported as an HTTPError.
+	DoRaw(ctx context.Context, method, subPath string, body []byte, headers http.Header) (*http.Response, []byte, *HTTPError)
+}
+
+const defaultAPIVersion = "v1"
+const resourcePath = "organisation/accounts"
+const servicePath = defaultAPIVersion + "/" + resourcePath
+const jsonContentType = "application/json"
+const contentType = "Content-Type"
+const schemaVersion = "X-Schema-Version"
+const idempotencyKey = "Idempotency-Key"
+const requestID = "X-Request-Id"
+const eTag = "ETag"
+const ifMatch = "If-Match"
+const defaultHealthPath = "/v1/health"
+const defaultUserAgent = "interview-accountapi/1.0 (+github.com/imochurad)"
+const defaultMaxResponseBytes = 10 << 20
+const minPageSize = 1
+const maxPageSize = 100
+
+// maxAutoPaginatedPages caps the number of pages List follows with WithAutoPaginate,
+// guarding against a server that keeps handing out fresh next links.
+const maxAutoPaginatedPages = 1000
+
+// filterKeys are the attributes the accounts API lets List filter by.
+var filterKeys = map[string]bool{
+	"account_number": true,
+	"bank_id":        true,
+	"bank_id_code":   true,
+	"country":        true,
+	"customer_id":    true,
+	"iban":           true,
+}
+
+type ReadInputStream func(io.Reader) ([]byte, error)
+type HttpGet func(context.Context, string) (*http.Response, error)
+type HttpHead func(context.Context, string) (*http.Response, error)
+type HttpPost func(ctx context.Context, url, contentType string, body io.Reader) (resp *http.Response, err error)
+type NewRequest func(context.Context, string, string, io.Reader) (*http.Request, error)
+type DoRequest func(*http.Request) (*http.Response, error)
+type Serialize func(any) ([]byte, error)
+type Deserialize func(data []byte, v any) error
+
+type idempotencyKeyCtx struct{}
+type ifMatchCtx struct{}
+
+// httpAccountsClientImpl is only ever written to while being built, by the options and init.
+// Any state added later on that changes between calls, e.g. a cache, needs guarding to keep it safe for concurrent use.
+type httpAccountsClientImpl struct {
+	host                  string
+	client                *http.Client
+	readInput             ReadInputStream
+	doHttpGet             HttpGet
+	doHttpHead            HttpHead
+	doHttpPost            HttpPost
+	createNewRequest      NewRequest
+	doRequest             DoRequest
+	serialize             Serialize
+	deserialize           Deserialize
+	retry                 *retryPolicy
+	retryMaxDelay         time.Duration
+	retryMaxElapsed       time.Duration
+	retryOnDecodeError    bool
+	headers               http.Header
+	requiredSchemaVersion string
+	skipNoopUpdates       bool
+	clientSideValidation  bool
+	logger                Logger
+	logBodies             bool
+	tracer                Tracer
+	metrics               Metrics
+	reportContentLength   bool
+	contentLengths        ContentLengthMetrics
+	reportBytes           bool
+	byteMetrics           ByteMetrics
+	compressRequests      bool
+	apiVersion            string
+	basePath              string
+	serviceUrl            string
+	limiter               *rate.Limiter
+	queue                 *requestQueue
+	healthPath            string
+	ownedTransport        *http.Transport
+	timeout               time.Duration
+	transportSettings     []func(*http.Transport)
+	userAgent             string
+	concurrency           int
+	autoRequestID         bool
+	correlate             bool
+	logging               bool
+	requestIDs            RequestIDMetrics
+	schema                *jsonschema.Schema
+	maxResponseBytes      int64
+	clock                 Clock
+	successStatus         func(operation string, code int) bool
+	dryRun                bool
+	defaultOrgID          string
+	payloadInMessage      int
+	streaming             bool
+	operationTimeouts     map[string]time.Duration
+	roundTrippers         []func(http.RoundTripper) http.RoundTripper
+	cache                 *fetchCache
+	maxStale              time.Duration
+	scrubber              fieldScrubber
+	resolveBaseURL        func() (string, error)
+	interceptResponse     func(*http.Response)
+	normalizeFetched      func(*AccountData)
+	autoPaginate          bool
+	verifyDelete          bool
+	validators            []AccountValidator
+}
+
+func (hac *httpAccountsClientImpl) Fetch(ctx context.Context, id string) (*AccountData, *HTTPError) {
+	account, _, httpErr := hac.FetchWithStaleness(ctx, id)
+	return account, httpErr
+}
+
+func (hac *httpAccountsClientImpl) FetchWithStaleness(ctx context.Context, id string) (*AccountData, bool, *HTTPError) {
+	if account, ok := hac.cache.get(id, hac.clock.Now()); ok {
+		return account, false, nil
+	}
+	account, _, httpErr := hac.FetchWithResponse(ctx, id)
+	if httpErr != nil {
+		if stale, ok := hac.cache.getStale(id, hac.clock.Now()); ok && degradable(httpErr) {
+			return stale, true, nil
+		}
+		return nil, false, httpErr
+	}
+	hac.cache.put(id, account, hac.clock.Now())
+	return account, false, nil
+}
+
+func (hac *httpAccountsClientImpl) FetchWithResponse(ctx context.Context, id string) (*AccountData, http.Header, *HTTPError) {
+	return hac.fetch(ctx, id, nil)
+}
+
+func (hac *httpAccountsClientImpl) FetchWithParams(ctx context.Context, id string, params url.Values) (*AccountData, *HTTPError) {
+	account, _, httpErr := hac.fetch(ctx, id, params)
+	return account, httpErr
+}
+
+// fetch gets the account identified by id, passing params along in the query string if there are any.
+func (hac *httpAccountsClientImpl) fetch(ctx context.Context, id string, params url.Values) (_ *AccountData, _ http.Header, e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "Fetch")
+	defer func() { endSpan(e) }()
+
+	if !isValidUUID(id) {
+		return nil, nil,
+			&HTTPError{
+				Message: msgInvalidID,
+				Kind:    KindValidation,
+			}
+	}
+
+	path := fmt.Sprintf("%s/%s", hac.serviceUrl, id)
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	resp, outcome, err := hac.sendWithRetry(ctx, true, func() (*http.Response, error) {
+		return hac.doHttpGet(ctx, path)
+	})
+	if err != nil {
+		return nil, nil,
+			outcome.annotate(placingError(err, "Get"))
+	}
+
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	responseData, httpErr := hac.readPayload(resp)
+	if httpErr != nil {
+		return nil, nil, httpErr
+	}
+
+	if !hac.succeeded(http.StatusOK, resp, "Get") {
+		return nil, nil,
+			outcome.annotate(hac.unexpectedStatusCode(http.StatusOK, resp, "Get", responseData))
+	}
+
+	if httpErr := expectJsonContentType(resp, responseData); httpErr != nil {
+		return nil, nil, httpErr
+	}
+
+	if httpErr := expectBody(resp, responseData); httpErr != nil {
+		return nil, nil, httpErr
+	}
+
+	if httpErr := hac.expectSchemaVersion(resp, responseData); httpErr != nil {
+		return nil, nil, httpErr
+	}
+
+	responseEnvelope, httpErr := hac.deserializeToResponseEnvelope(responseData)
+	if httpErr != nil {
+		return nil, nil, httpErr
+	}
+
+	account, httpErr := accountDataOrError(responseEnvelope, responseData)
+	if httpErr != nil {
+		return nil, nil, httpErr
+	}
+	hac.normalize(account)
+	return account, resp.Header.Clone(), nil
+}
+
+func (hac *httpAccountsClientImpl) FetchHead(ctx context.Context, id string) (_ http.Header, e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "FetchHead")
+	defer func() { endSpan(e) }()
+
+	return hac.fetchHead(ctx, id)
+}
+
+// fetchHead is FetchHead without a span of its own, for operations built on it.
+func (hac *httpAccountsClientImpl) fetchHead(ctx context.Context, id string) (http.Header, *HTTPError) {
+	if !isValidUUID(id) {
+		return nil,
+			&HTTPError{
+				Message: msgInvalidID,
+				Kind:    KindValidation,
+			}
+	}
+
+	resp, err := hac.doHttpHead(ctx, fmt.Sprintf("%s/%s", hac.serviceUrl, id))
+
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		return nil, placingError(err, "Head")
+	}
+
+	if !hac.succeeded(http.StatusOK, resp, "Head") {
+		// a HEAD response carries no body, the payload is read for the sake of draining the connection
+		responseData, httpErr := hac.readPayload(resp)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+		return nil, hac.unexpectedStatusCode(http.StatusOK, resp, "Head", responseData)
+	}
+	return resp.Header.Clone(), nil
+}
+
+func (hac *httpAccountsClientImpl) FetchWithETag(ctx context.Context, id string) (*AccountData, string, *HTTPError) {
+	account, headers, httpErr := hac.FetchWithResponse(ctx, id)
+	if httpErr != nil {
+		return nil, "", httpErr
+	}
+	return account, headers.Get(eTag), nil
+}
+
+func (hac *httpAccountsClientImpl) Exists(ctx context.Context, id string) (_ bool, e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "Exists")
+	defer func() { endSpan(e) }()
+
+	_, httpErr := hac.fetchHead(ctx, id)
+	if httpErr.IsNotFound() {
+		return false, nil
+	}
+	if httpErr != nil {
+		return false, httpErr
+	}
+	return true, nil
+}
+
+func (hac *httpAccountsClientImpl) FetchVersion(ctx context.Context, id string) (int64, *HTTPError) {
+	account, _, httpErr := hac.fetch(ctx, id, nil)
+	if httpErr != nil {
+		return 0, httpErr
+	}
+	if account.Version == nil {
+		return 0,
+			&HTTPError{
+				Message: msgNoVersion,
+				Kind:    KindServer,
+			}
+	}
+	return *account.Version, nil
+}
+
+func (hac *httpAccountsClientImpl) Create(ctx context.Context, account *AccountData) (*AccountData, *HTTPError) {
+	return hac.create(hac.createContext(ctx), account)
+}
+
+// createContext prepares the context of a Create, equipping it with an idempotency key when retrying is enabled.
+func (hac *httpAccountsClientImpl) createContext(ctx context.Context) context.Context {
+	if hac.retry != nil {
+		// every attempt carries the same key, so that the server can deduplicate a create that got retried
+		ctx = withIdempotencyKey(ctx, uuid.NewString())
+	}
+	return ctx
+}
+
+func (hac *httpAccountsClientImpl) CreateWithIdempotencyKey(ctx context.Context, key string, account *AccountData) (*AccountData, *HTTPError) {
+	if key == "" {
+		return nil,
+			&HTTPError{
+				Message: msgEmptyIdempotencyKey,
+				Kind:    KindValidation,
+			}
+	}
+	return hac.create(withIdempotencyKey(ctx, key), account)
+}
+
+func (hac *httpAccountsClientImpl) create(ctx context.Context, account *AccountData) (_ *AccountData, e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "Create")
+	defer func() { endSpan(e) }()
+
+	requestData, httpErr := hac.createPayload(account)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	resp, outcome, err := hac.sendWithRetry(ctx, false, func() (*http.Response, error) {
+		// every attempt needs a fresh reader, the previous one has already been consumed
+		return hac.doHttpPost(ctx, hac.serviceUrl, jsonContentType, bytes.NewReader(requestData))
+	})
+	return hac.createdAccountOrError(ctx, resp, outcome, err)
+}
+
+// createPayload checks the account as configured and serializes it into the body of a Create request.
+func (hac *httpAccountsClientImpl) createPayload(account *AccountData) ([]byte, *HTTPError) {
+	if account != nil && account.OrganisationID == "" && hac.defaultOrgID != "" {
+		// the caller's account is left alone, only the copy sent over carries the default
+		withDefault := *account
+		withDefault.OrganisationID = hac.defaultOrgID
+		account = &withDefault
+	}
+
+	if hac.clientSideValidation {
+		if httpErr := validateAccount(account); httpErr != nil {
+			return nil, httpErr
+		}
+	}
+	if httpErr := hac.runValidators(account); httpErr != nil {
+		return nil, httpErr
+	}
+
+	requestEnvelope := Envelope[AccountData]{
+		Data: account,
+	}
+	requestData, err := hac.serialize(requestEnvelope)
+	if err != nil {
+		return nil,
+			&HTTPError{
+				Cause:   err,
+				Message: msgSerializingPayload,
+				Kind:    KindSerialization,
+			}
+	}
+	if httpErr := hac.validateSchema(requestData); httpErr != nil {
+		return nil, httpErr
+	}
+	return requestData, nil
+}
+
+func (hac *httpAccountsClientImpl) BuildCreateRequest(ctx context.Context, account *AccountData) (*http.Request, *HTTPError) {
+	requestData, httpErr := hac.createPayload(account)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	req, err := hac.postRequest(hac.createContext(ctx), hac.serviceUrl, jsonContentType, bytes.NewReader(requestData))
+	if err != nil {
+		return nil, preparingError(err, "Post")
+	}
+	return req, nil
+}
+
+func (hac *httpAccountsClientImpl) CreateFromReader(ctx context.Context, r io.Reader) (_ *AccountData, e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "CreateFromReader")
+	defer func() { endSpan(e) }()
+
+	if r == nil {
+		return nil,
+			&HTTPError{
+				Message: msgNilReader,
+				Kind:    KindValidation,
+			}
+	}
+
+	// a stream can only be consumed once, so there is no retrying here
+	resp, err := hac.doHttpPost(ctx, hac.serviceUrl, jsonContentType, r)
+	return hac.createdAccountOrError(ctx, resp, retryOutcome{attempts: 1}, err)
+}
+
+func (hac *httpAccountsClientImpl) createdAccountOrError(ctx context.Context, resp *http.Response, outcome retryOutcome, err error) (*AccountData, *HTTPError) {
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		return nil,
+			outcome.annotate(placingError(err, "Post"))
+	}
+
+	responseData, httpErr := hac.readPayload(resp)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	if resp.StatusCode == http.StatusAccepted && !hac.succeeded(http.StatusCreated, resp, "Post") {
+		return hac.awaitCompletion(ctx, resp)
+	}
+
+	if !hac.succeeded(http.StatusCreated, resp, "Post") {
+		return nil, outcome.annotate(hac.unexpectedStatusCode(http.StatusCreated, resp, "Post", responseData))
+	}
+
+	if httpErr := expectJsonContentType(resp, responseData); httpErr != nil {
+		return nil, httpErr
+	}
+
+	if httpErr := hac.expectSchemaVersion(resp, responseData); httpErr != nil {
+		return nil, httpErr
+	}
+
+	responseEnvelope, httpErr := hac.deserializeToResponseEnvelope(responseData)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	account, httpErr := accountDataOrError(responseEnvelope, responseData)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	// the id may be reused after a delete made by someone else, whatever was cached under it is gone now
+	hac.cache.invalidate(account.ID)
+	return account, nil
+}
+
+func (hac *httpAccountsClientImpl) Update(ctx context.Context, id string, version int64, account *AccountData) (_ *AccountData, e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "Update")
+	defer func() { endSpan(e) }()
+
+	if httpErr := checkUpdate(id, version, account); httpErr != nil {
+		return nil, httpErr
+	}
+
+	if hac.skipNoopUpdates {
+		if current := hac.unchangedBy(ctx, id, version, account); current != nil {
+			return current, nil
+		}
+	}
+
+	// whatever the outcome, the cached account can't be trusted anymore, a conflict means it is stale already
+	defer hac.cache.invalidate(id)
+
+	req, httpErr := hac.updateRequest(ctx, id, version, account)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	resp, err := hac.doRequest(req)
+
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		return nil, placingError(err, "Patch")
+	}
+
+	responseData, httpErr := hac.readPayload(resp)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	if !hac.succeeded(http.StatusOK, resp, "Patch") {
+		return nil, hac.unexpectedStatusCode(http.StatusOK, resp, "Patch", responseData)
+	}
+
+	if httpErr := expectJsonContentType(resp, responseData); httpErr != nil {
+		return nil, httpErr
+	}
+
+	if httpErr := hac.expectSchemaVersion(resp, responseData); httpErr != nil {
+		return nil, httpErr
+	}
+
+	responseEnvelope, httpErr := hac.deserializeToResponseEnvelope(responseData)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	return accountDataOrError(responseEnvelope, responseData)
+}
+
+func (hac *httpAccountsClientImpl) BuildUpdateRequest(ctx context.Context, id string, version int64, account *AccountData) (*http.Request, *HTTPError) {
+	if httpErr := checkUpdate(id, version, account); httpErr != nil {
+		return nil, httpErr
+	}
+	return hac.updateRequest(ctx, id, version, account)
+}
+
+// checkUpdate rejects the arguments of an Update that can't make a valid request.
+func checkUpdate(id string, version int64, account *AccountData) *HTTPError {
+	if !isValidUUID(id) {
+		return &HTTPError{
+			Message: msgInvalidID,
+			Kind:    KindValidation,
+		}
+	}
+
+	if account == nil {
+		return &HTTPError{
+			Message: msgNilAccount,
+			Kind:    KindValidation,
+		}
+	}
+
+	if version < 0 {
+		return &HTTPError{
+			Message: msgNegativeVersion,
+			Kind:    KindValidation,
+		}
+	}
+	return nil
+}
+
+// updateRequest prepares the PATCH of an Update, its arguments having been checked already.
+func (hac *httpAccountsClientImpl) updateRequest(ctx context.Context, id string, version int64, account *AccountData) (*http.Request, *HTTPError) {
+	// the caller's account is left alone, only the copy sent over carries the version
+	patch := *account
+	patch.Version = &version
+	if patch.ID == "" {
+		patch.ID = id
+	}
+	if httpErr := hac.runValidators(&patch); httpErr != nil {
+		return nil, httpErr
+	}
+
+	requestData, err := hac.serialize(Envelope[AccountData]{Data: &patch})
+	if err != nil {
+		return nil,
+			&HTTPError{
+				Cause:   err,
+				Message: msgSerializingPayload,
+				Kind:    KindSerialization,
+			}
+	}
+	if httpErr := hac.validateSchema(requestData); httpErr != nil {
+		return nil, httpErr
+	}
+
+	fullPath := fmt.Sprintf("%s/%s", hac.serviceUrl, id)
+	req, err := hac.newRequest(ctx, http.MethodPatch, fullPath, bytes.NewReader(requestData))
+	if err != nil {
+		return nil, preparingError(err, "Patch")
+	}
+	req.Header.Set(contentType, jsonContentType)
+	return req, nil
+}
+
+// unchangedBy returns the current state of the account if applying the update would not change anything.
+// Whenever that can't be established, because the fetch failed or the account has moved on to another version
+// in the meantime, nil is returned and the update goes ahead, leaving it up to the server to detect the conflict.
+// The account is fetched from the server, a cached one could be stale and hide the conflict.
+func (hac *httpAccountsClientImpl) unchangedBy(ctx context.Context, id string, version int64, account *AccountData) *AccountData {
+	current, _, httpErr := hac.fetch(ctx, id, nil)
+	if httpErr != nil || current.Version == nil || *current.Version != version {
+		return nil
+	}
+	if !EqualAccountData(MergeAttributes(current, account.Attributes), current) {
+		return nil
+	}
+	return current
+}
+
+func (hac *httpAccountsClientImpl) UpdateIfMatch(ctx context.Context, id string, version int64, account *AccountData, ifMatch string) (*AccountData, *HTTPError) {
+	if ifMatch == "" {
+		return nil,
+			&HTTPError{
+				Message: msgEmptyIfMatch,
+				Kind:    KindValidation,
+			}
+	}
+	return hac.Update(withIfMatch(ctx, ifMatch), id, version, account)
+}
+
+func (hac *httpAccountsClientImpl) UpdateWithRetry(ctx context.Context, id string, mutate func(*AccountData) *AccountData, maxAttempts int) (*AccountData, *HTTPError) {
+	if mutate == nil {
+		return nil,
+			&HTTPError{
+				Message: msgNilMutate,
+				Kind:    KindValidation,
+			}
+	}
+	if maxAttempts < 1 {
+		return nil,
+			&HTTPError{
+				Message: msgMaxAttemptsNotPositive,
+				Kind:    KindValidation,
+			}
+	}
+
+	for attempt := 1; ; attempt++ {
+		// fetched from the server even with WithFetchCache, a stale version would only make the update conflict
+		current, _, httpErr := hac.fetch(ctx, id, nil)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+		if current.Version == nil {
+			return nil,
+				&HTTPError{
+					Message: msgNoVersion,
+					Kind:    KindServer,
+				}
+		}
+
+		updated, httpErr := hac.Update(ctx, id, *current.Version, mutate(current))
+		if !httpErr.IsConflict() {
+			return updated, httpErr
+		}
+		if attempt >= maxAttempts {
+			return nil, withAttempts(attempt, httpErr)
+		}
+	}
+}
+
+func (hac *httpAccountsClientImpl) Delete(ctx context.Context, id string, version int64) (e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "Delete")
+	defer func() { endSpan(e) }()
+
+	req, httpErr := hac.BuildDeleteRequest(ctx, id, version)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	defer hac.cache.invalidate(id)
+
+	resp, err := hac.doRequest(req)
+
+	if resp != nil {
+		// drained even on success, a body the server had no reason to send keeps the connection from being reused
+		defer discard(resp)
+	}
+
+	if err != nil {
+		return placingError(err, "Delete")
+	}
+
+	if !hac.succeeded(http.StatusNoContent, resp, "Delete") {
+		responseData, httpErr := hac.readPayload(resp)
+		if httpErr != nil {
+			return httpErr
+		}
+		return hac.unexpectedStatusCode(http.StatusNoContent, resp, "Delete", responseData)
+	}
+	if hac.verifyDelete && resp.StatusCode == http.StatusNoContent {
+		return hac.verifyDeleted(ctx, id)
+	}
+	return nil
+}
+
+// verifyDeleted fetches the account just deleted, which the server must no longer know about,
+// see WithDeleteVerification.
+func (hac *httpAccountsClientImpl) verifyDeleted(ctx context.Context, id string) *HTTPError {
+	_, _, httpErr := hac.fetch(ctx, id, nil)
+	if httpErr.IsNotFound() {
+		return nil
+	}
+	if httpErr != nil {
+		return httpErr
+	}
+	return &HTTPError{
+		Message: msgStillExistsAfterDelete,
+		Kind:    KindServer,
+	}
+}
+
+func (hac *httpAccountsClientImpl) BuildDeleteRequest(ctx context.Context, id string, version int64) (*http.Request, *HTTPError) {
+	if !isValidUUID(id) {
+		return nil,
+			&HTTPError{
+				Message: msgInvalidID,
+				Kind:    KindValidation,
+			}
+	}
+
+	if version < 0 {
+		return nil,
+			&HTTPError{
+				Message: msgNegativeVersion,
+				Kind:    KindValidation,
+			}
+	}
+
+	fullPath := fmt.Sprintf("%s/%s?version=%d", hac.serviceUrl, id, version)
+	req, err := hac.newRequest(ctx, http.MethodDelete, fullPath, nil)
+	if err != nil {
+		return nil, preparingError(err, "Delete")
+	}
+	return req, nil
+}
+
+func (hac *httpAccountsClientImpl) DeleteIfExists(ctx context.Context, id string, version int64) *HTTPError {
+	httpErr := hac.Delete(ctx, id, version)
+	if httpErr.IsNotFound() {
+		return nil
+	}
+	return httpErr
+}
+
+func (hac *httpAccountsClientImpl) DeleteIfMatch(ctx context.Context, id string, version int64, ifMatch string) *HTTPError {
+	if ifMatch == "" {
+		return &HTTPError{
+			Message: msgEmptyIfMatch,
+			Kind:    KindValidation,
+		}
+	}
+	return hac.Delete(withIfMatch(ctx, ifMatch), id, version)
+}
+
+func (hac *httpAccountsClientImpl) FetchThenDelete(ctx context.Context, id string) *HTTPError {
+	version, httpErr := hac.FetchVersion(ctx, id)
+	if httpErr != nil {
+		return httpErr
+	}
+	return hac.Delete(ctx, id, version)
+}
+
+func (hac *httpAccountsClientImpl) List(ctx context.Context, pageNumber, pageSize int) (_ []*AccountData, _ *Links, e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "List")
+	defer func() { endSpan(e) }()
+
+	if hac.autoPaginate && pageNumber == 0 && pageSize == 0 {
+		accounts, httpErr := hac.listAll(ctx, maxAutoPaginatedPages)
+		return accounts, nil, httpErr
+	}
+	return hac.list(ctx, nil, pageNumber, pageSize)
+}
+
+func (hac *httpAccountsClientImpl) ListFiltered(ctx context.Context, filters map[string]string, pageNumber, pageSize int) (_ []*AccountData, _ *Links, e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "ListFiltered")
+	defer func() { endSpan(e) }()
+
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !filterKeys[key] {
+			return nil, nil,
+				&HTTPError{
+					Message: fmt.Sprintf(msgUnsupportedFilterKey, key),
+					Kind:    KindValidation,
+				}
+		}
+	}
+
+	return hac.list(ctx, filters, pageNumber, pageSize)
+}
+
+func (hac *httpAccountsClientImpl) list(ctx context.Context, filters map[string]string, pageNumber, pageSize int) ([]*AccountData, *Links, *HTTPError) {
+	if pageSize < minPageSize || pageSize > maxPageSize {
+		return nil, nil,
+			&HTTPError{
+				Message: fmt.Sprintf(msgPageSizeOutOfRange, minPageSize, maxPageSize),
+				Kind:    KindValidation,
+			}
+	}
+
+	if pageNumber < 0 {
+		return nil, nil,
+			&HTTPError{
+				Message: msgNegativePageNumber,
+				Kind:    KindValidation,
+			}
+	}
+
+	responseEnvelope, httpErr := hac.listPage(ctx, hac.pagePath(filters, pageNumber, pageSize))
+	if httpErr != nil {
+		return nil, nil, httpErr
+	}
+
+	accounts := responseEnvelope.Data
+	if accounts == nil {
+		accounts = make([]*AccountData, 0)
+	}
+	hac.normalize(accounts...)
+	return accounts, responseEnvelope.Links, nil
+}
+
+func (hac *httpAccountsClientImpl) ListAll(ctx context.Context) (_ []*AccountData, e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "ListAll")
+	defer func() { endSpan(e) }()
+
+	return hac.listAll(ctx, 0)
+}
+
+// listAll follows the links.next url from the first page on, giving up after maxPages pages unless maxPages is 0.
+func (hac *httpAccountsClientImpl) listAll(ctx context.Context, maxPages int) ([]*AccountData, *HTTPError) {
+	accounts := make([]*AccountData, 0)
+	visited := make(map[string]bool)
+	path := hac.pagePath(nil, 0, maxPageSize)
+
+	for pages := 1; ; pages++ {
+		responseEnvelope, httpErr := hac.listPage(ctx, path)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+		hac.normalize(responseEnvelope.Data...)
+		accounts = append(accounts, responseEnvelope.Data...)
+
+		if !responseEnvelope.Links.HasNext() {
+			return accounts, nil
+		}
+
+		if pages == maxPages {
+			return nil,
+				&HTTPError{
+					Message: fmt.Sprintf(msgTooManyPages, maxPages),
+					Kind:    KindServer,
+				}
+		}
+
+		next := responseEnvelope.Links.Next
+		if visited[next] {
+			return nil,
+				&HTTPError{
+					Message: msgPaginationCycle,
+					Kind:    KindServer,
+				}
+		}
+		visited[next] = true
+
+		nextPath, err := hac.resolveLink(next)
+		if err != nil {
+			return nil,
+				&HTTPError{
+					Cause:   err,
+					Message: msgParsingNextLink,
+					Kind:    KindServer,
+				}
+		}
+		path = nextPath
+	}
 }
 
-const servicePath = "v1/organisation/accounts"
-const jsonContentType = "application/json"
-const contentType = "Content-Type"
-
-type ReadInputStream func(io.Reader) ([]byte, error)
-type HttpGet func(string) (*http.Response, error)
-type HttpPost func(url, contentType string, body io.Reader) (resp *http.Response, err error)
-type NewRequest func(string, string, io.Reader) (*http.Request, error)
-type DoRequest func(*http.Request) (*http.Response, error)
-type Serialize func(any) ([]byte, error)
+// resolveLink turns a link handed out by the server into a full url,
+// links are usually relative to the host, e.g. /v1/organisation/accounts?page[number]=1
+func (hac *httpAccountsClientImpl) resolveLink(link string) (string, error) {
+	base, err := url.Parse(hac.host)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := base.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	return resolved.String(), nil
+}
 
-type httpAccountsClientImpl struct {
-	host             string
-	client           *http.Client
-	readInput        ReadInputStream
-	doHttpGet        HttpGet
-	doHttpPost       HttpPost
-	createNewRequest NewRequest
-	doRequest        DoRequest
-	serialize        Serialize
+func (hac *httpAccountsClientImpl) pagePath(filters map[string]string, pageNumber, pageSize int) string {
+	query := url.Values{}
+	for key, value := range filters {
+		query.Set("filter["+key+"]", value)
+	}
+	query.Set("page[number]", strconv.Itoa(pageNumber))
+	query.Set("page[size]", strconv.Itoa(pageSize))
+	return fmt.Sprintf("%s?%s", hac.serviceUrl, query.Encode())
 }
 
-func (hac *httpAccountsClientImpl) Fetch(id string) (*AccountData, *HTTPError) {
-	if !isValidUUID(id) {
-		return nil,
-			&HTTPError{
-				Message: "id must be a valid uuid",
-			}
+func (hac *httpAccountsClientImpl) listPage(ctx context.Context, path string) (*ListEnvelope[AccountData], *HTTPError) {
+	if hac.canStream() {
+		return hac.streamListPage(ctx, path)
 	}
 
-	path := fmt.Sprintf("%s/%s/%s", hac.host, servicePath, id)
-	resp, err := hac.doHttpGet(path)
+	resp, outcome, err := hac.sendWithRetry(ctx, true, func() (*http.Response, error) {
+		return hac.doHttpGet(ctx, path)
+	})
 	if err != nil {
 		return nil,
-			&HTTPError{
-				Cause:   err,
-				Message: "Error placing a Get Http request",
-			}
+			outcome.annotate(placingError(err, "Get"))
 	}
 
 	if resp != nil {
@@ -89,134 +1091,214 @@ func (hac *httpAccountsClientImpl) Fetch(id string) (*AccountData, *HTTPError) {
 		return nil, httpErr
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	if !hac.succeeded(http.StatusOK, resp, "List") {
 		return nil,
-			unexpectedStatusCode(http.StatusOK, resp.StatusCode, "Get", responseData)
+			outcome.annotate(hac.unexpectedStatusCode(http.StatusOK, resp, "List", responseData))
 	}
 
-	cType := resp.Header.Get(contentType)
-	if !strings.HasPrefix(cType, jsonContentType) {
-		return nil,
-			&HTTPError{
-				StatusCode:      resp.StatusCode,
-				Message:         fmt.Sprintf("Unexpected  %s, expecting %s, got %s", contentType, jsonContentType, cType),
-				ResponsePayload: responseData,
-			}
+	if httpErr := expectJsonContentType(resp, responseData); httpErr != nil {
+		return nil, httpErr
 	}
 
-	responseEnvelope, httpErr := deserializeToResponseEnvelope(responseData)
-	if httpErr != nil {
+	if httpErr := hac.expectSchemaVersion(resp, responseData); httpErr != nil {
 		return nil, httpErr
 	}
 
-	return accountDataOrError(responseEnvelope, responseData)
+	return hac.deserializeToListEnvelope(responseData)
 }
 
-func (hac *httpAccountsClientImpl) Create(account *AccountData) (*AccountData, *HTTPError) {
-	requestEnvelope := Envelope[AccountData]{
-		Data: account,
-	}
-	requestData, err := hac.serialize(requestEnvelope)
-	if err != nil {
-		return nil,
-			&HTTPError{
-				Cause:   err,
-				Message: "Unable to serialize payload",
-			}
-	}
+func (hac *httpAccountsClientImpl) HealthCheck(ctx context.Context) (e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "HealthCheck")
+	defer func() { endSpan(e) }()
 
-	reader := bytes.NewReader(requestData)
-	resp, err := hac.doHttpPost(hac.host+"/"+servicePath, jsonContentType, reader)
+	resp, err := hac.doHttpGet(ctx, hac.host+hac.healthPath)
 
 	if resp != nil {
 		defer resp.Body.Close()
 	}
 
 	if err != nil {
-		return nil,
-			&HTTPError{
-				Cause:   err,
-				Message: "Error placing a Post Http request",
-			}
+		return placingError(err, "Get")
 	}
 
 	responseData, httpErr := hac.readPayload(resp)
 	if httpErr != nil {
-		return nil, httpErr
+		return httpErr
 	}
 
-	if resp.StatusCode != http.StatusCreated {
-		return nil, unexpectedStatusCode(http.StatusCreated, resp.StatusCode, "Post", responseData)
+	if resp.StatusCode != http.StatusOK {
+		return hac.unexpectedStatusCode(http.StatusOK, resp, "HealthCheck", responseData)
 	}
+	return nil
+}
 
-	responseEnvelope, httpErr := deserializeToResponseEnvelope(responseData)
-	if httpErr != nil {
-		return nil, httpErr
-	}
+func (hac *httpAccountsClientImpl) Ping(ctx context.Context) (e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "Ping")
+	defer func() { endSpan(e) }()
 
-	return accountDataOrError(responseEnvelope, responseData)
+	resp, err := hac.doHttpGet(ctx, hac.pagePath(nil, 0, 1))
+	if err != nil {
+		return placingError(err, "Get")
+	}
+	// whatever the server had to say doesn't matter, but the connection has to go back to the pool
+	discard(resp)
+	return nil
 }
 
-func (hac *httpAccountsClientImpl) Delete(id string, version int64) (e *HTTPError) {
-	if !isValidUUID(id) {
+func (hac *httpAccountsClientImpl) Warmup(ctx context.Context, n int) (e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "Warmup")
+	defer func() { endSpan(e) }()
+
+	if n < 1 {
 		return &HTTPError{
-			Message: "id must be a valid uuid",
+			Message: msgWarmupNotPositive,
+			Kind:    KindValidation,
 		}
 	}
 
-	fullPath := fmt.Sprintf("%s/%s/%s?version=%d", hac.host, servicePath, id, version)
-
-	req, err := hac.createNewRequest(http.MethodDelete, fullPath, nil)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = hac.touchHost(ctx)
+		}(i)
+	}
+	wg.Wait()
 
-	if err != nil {
-		return &HTTPError{
-			Cause:   err,
-			Message: "Error preparing Delete Http request",
+	for _, err := range errs {
+		if err != nil {
+			return &HTTPError{
+				Cause:   err,
+				Message: msgWarmingUp,
+				Kind:    KindNetwork,
+			}
 		}
 	}
+	return nil
+}
 
+func (hac *httpAccountsClientImpl) touchHost(ctx context.Context) error {
+	req, err := hac.newRequest(ctx, http.MethodHead, hac.host, nil)
+	if err != nil {
+		return err
+	}
 	resp, err := hac.doRequest(req)
+	if err != nil {
+		return err
+	}
+	// the body has to be drained for the connection to go back to the pool
+	io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
 
-	if resp != nil {
-		defer resp.Body.Close()
+// expectBody makes sure a successful response carries a body at all, as some proxies hand out empty 200 responses,
+// which would otherwise be reported as invalid json.
+func expectBody(resp *http.Response, responseData *[]byte) *HTTPError {
+	if len(*responseData) == 0 {
+		return &HTTPError{
+			StatusCode:      resp.StatusCode,
+			Status:          resp.Status,
+			Message:         msgEmptyBody,
+			Kind:            KindServer,
+			ResponsePayload: responseData,
+			RequestID:       resp.Header.Get(requestID),
+		}
 	}
+	return nil
+}
 
-	if err != nil {
+func expectJsonContentType(resp *http.Response, responseData *[]byte) *HTTPError {
+	cType := resp.Header.Get(contentType)
+	if !strings.HasPrefix(cType, jsonContentType) {
 		return &HTTPError{
-			Cause:   err,
-			Message: "Error placing Delete Http request",
+			StatusCode:      resp.StatusCode,
+			Status:          resp.Status,
+			Message:         fmt.Sprintf(msgUnexpectedHeader, contentType, jsonContentType, cType),
+			Kind:            KindSerialization,
+			ResponsePayload: responseData,
+			RequestID:       resp.Header.Get(requestID),
 		}
 	}
+	return nil
+}
 
-	if resp.StatusCode != http.StatusNoContent {
-		responseData, httpErr := hac.readPayload(resp)
-		if httpErr != nil {
-			return httpErr
+// expectSchemaVersion makes sure the response payload follows the schema version the client was told to require, if any.
+func (hac *httpAccountsClientImpl) expectSchemaVersion(resp *http.Response, responseData *[]byte) *HTTPError {
+	if hac.requiredSchemaVersion == "" {
+		return nil
+	}
+	version := resp.Header.Get(schemaVersion)
+	if version != hac.requiredSchemaVersion {
+		return &HTTPError{
+			StatusCode:      resp.StatusCode,
+			Status:          resp.Status,
+			Message:         fmt.Sprintf(msgUnexpectedHeader, schemaVersion, hac.requiredSchemaVersion, version),
+			Kind:            KindSerialization,
+			ResponsePayload: responseData,
+			RequestID:       resp.Header.Get(requestID),
 		}
-		return unexpectedStatusCode(http.StatusNoContent, resp.StatusCode, "Delete", responseData)
 	}
 	return nil
 }
 
-func deserializeToResponseEnvelope(responseData *[]byte) (*Envelope[AccountData], *HTTPError) {
+func (hac *httpAccountsClientImpl) deserializeToResponseEnvelope(responseData *[]byte) (*Envelope[AccountData], *HTTPError) {
 	var responseEnvelope *Envelope[AccountData]
-	err := json.Unmarshal(*responseData, &responseEnvelope)
+	err := hac.deserialize(*responseData, &responseEnvelope)
 
 	if err != nil {
 		return nil, &HTTPError{
-			Cause:           err,
-			Message:         "Error deserializing json",
+			Cause:           dataShapeError(err, "object"),
+			Message:         msgDeserializing,
+			Kind:            KindSerialization,
+			ResponsePayload: responseData,
+		}
+	}
+	if responseEnvelope == nil {
+		// a json null, reported as an empty object by accountDataOrError
+		responseEnvelope = &Envelope[AccountData]{}
+	}
+	return responseEnvelope, nil
+}
+
+func (hac *httpAccountsClientImpl) deserializeToListEnvelope(responseData *[]byte) (*ListEnvelope[AccountData], *HTTPError) {
+	var responseEnvelope *ListEnvelope[AccountData]
+	err := hac.deserialize(*responseData, &responseEnvelope)
+
+	if err != nil || responseEnvelope == nil {
+		return nil, &HTTPError{
+			Cause:           dataShapeError(err, "array"),
+			Message:         msgDeserializing,
+			Kind:            KindSerialization,
 			ResponsePayload: responseData,
 		}
 	}
 	return responseEnvelope, nil
 }
 
+// dataShapeError words the failure to decode a data member that is not a json object or array, as expected
+// by the operation, so that a single account mistaken for a list or the other way round is told as such.
+// Any other error, including the ones of a deserializer other than json.Unmarshal, is returned as is.
+func dataShapeError(err error, expected string) error {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field != "data" {
+		return err
+	}
+	shapes := map[string]string{"object": "an object", "array": "an array"}
+	actual, ok := shapes[typeErr.Value]
+	if !ok {
+		actual = "a scalar"
+	}
+	return fmt.Errorf("expecting data to be %s, got %s", shapes[expected], actual)
+}
+
 func accountDataOrError(responseEnvelope *Envelope[AccountData], responseData *[]byte) (*AccountData, *HTTPError) {
 	// making sure we are not returning null for the http error and then for the value, making it either-or
 	if responseEnvelope.Data == nil {
 		return nil, &HTTPError{
-			Message:         fmt.Sprintf("Got an empty object after deserialization, json payload was an empty object?"),
+			Message:         msgEmptyObject,
+			Kind:            KindSerialization,
 			ResponsePayload: responseData,
 		}
 	}
@@ -224,115 +1306,399 @@ func accountDataOrError(responseEnvelope *Envelope[AccountData], responseData *[
 }
 
 func (hac *httpAccountsClientImpl) readPayload(resp *http.Response) (*[]byte, *HTTPError) {
-	responseData, err := hac.readInput(resp.Body)
+	responseData, err := hac.readLimited(resp.Body)
 
+	if errors.Is(err, errResponseTooLarge) {
+		return nil, &HTTPError{
+			Cause:   err,
+			Message: msgResponseTooLarge,
+			Kind:    KindNetwork,
+		}
+	}
 	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) && hasDeclaredLength(resp) {
+			return nil, &HTTPError{
+				Cause:   err,
+				Message: msgTruncatedBody,
+				Kind:    KindNetwork,
+			}
+		}
+		return nil, &HTTPError{
+			Cause:   err,
+			Message: msgProcessingBody,
+			Kind:    KindNetwork,
+		}
+	}
+
+	// the server told us how many bytes to expect, anything else means the payload got cut short
+	if hasDeclaredLength(resp) && int64(len(responseData)) != resp.ContentLength {
+		return nil, &HTTPError{
+			Message:         msgTruncatedBody,
+			Kind:            KindNetwork,
+			ResponsePayload: &responseData,
+		}
+	}
+
+	// the declared length is that of the compressed body, hence decompressing only after checking it
+	decompressed, err := hac.decompress(resp, responseData)
+	if errors.Is(err, errResponseTooLarge) {
 		return nil, &HTTPError{
 			Cause:   err,
-			Message: "Error processing response body",
+			Message: msgResponseTooLarge,
+			Kind:    KindNetwork,
+		}
+	}
+	if err != nil {
+		return nil, &HTTPError{
+			Cause:           err,
+			Message:         msgDecompressingBody,
+			Kind:            KindSerialization,
+			ResponsePayload: &responseData,
+		}
+	}
+	hac.countReceived(resp, int64(len(responseData)), int64(len(decompressed)))
+	hac.logBody(resp, decompressed)
+	return &decompressed, nil
+}
+
+var errResponseTooLarge = errors.New("response body is larger than the configured maximum")
+
+// readLimited reads r to the end, giving up with errResponseTooLarge once more than maxResponseBytes were read.
+// A single byte past the limit is enough to tell, so no more than that is ever held in memory.
+func (hac *httpAccountsClientImpl) readLimited(r io.Reader) ([]byte, error) {
+	data, err := hac.readInput(io.LimitReader(r, hac.maxResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > hac.maxResponseBytes {
+		return nil, errResponseTooLarge
+	}
+	return data, nil
+}
+
+// newRequest prepares a request carrying all the headers configured on the client.
+func (hac *httpAccountsClientImpl) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	path, err := hac.resolvedPath(path)
+	if err != nil {
+		return nil, err
+	}
+	req, err := hac.createNewRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", hac.userAgent)
+	for key, values := range hac.headers {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	if hac.autoRequestID && req.Header.Get(requestID) == "" {
+		req.Header.Set(requestID, uuid.NewString())
+	}
+	if key, ok := ctx.Value(idempotencyKeyCtx{}).(string); ok {
+		req.Header.Set(idempotencyKey, key)
+	}
+	if tag, ok := ctx.Value(ifMatchCtx{}).(string); ok {
+		req.Header.Set(ifMatch, tag)
+	}
+	// asking for gzip explicitly keeps the transport from decompressing transparently, readPayload takes care of it
+	req.Header.Set(acceptEncoding, gzipEncoding)
+	return req, nil
+}
+
+func (hac *httpAccountsClientImpl) get(ctx context.Context, path string) (*http.Response, error) {
+	req, err := hac.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return hac.doRequest(req)
+}
+
+func (hac *httpAccountsClientImpl) head(ctx context.Context, path string) (*http.Response, error) {
+	req, err := hac.newRequest(ctx, http.MethodHead, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return hac.doRequest(req)
+}
+
+func (hac *httpAccountsClientImpl) post(ctx context.Context, path, cType string, body io.Reader) (*http.Response, error) {
+	req, err := hac.postRequest(ctx, path, cType, body)
+	if err != nil {
+		return nil, err
+	}
+	return hac.doRequest(req)
+}
+
+func (hac *httpAccountsClientImpl) postRequest(ctx context.Context, path, cType string, body io.Reader) (*http.Request, error) {
+	if hac.compressRequests {
+		uncompressed := &countingReader{r: body}
+		compressed, err := gzipped(uncompressed)
+		if err != nil {
+			return nil, err
 		}
+		body = compressed
+		ctx = withUncompressedSize(ctx, uncompressed.n)
+	}
+	req, err := hac.newRequest(ctx, http.MethodPost, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(contentType, cType)
+	if hac.compressRequests {
+		req.Header.Set(contentEncoding, gzipEncoding)
+	}
+	return req, nil
+}
+
+// withIdempotencyKey marks the requests sent with the returned context with an Idempotency-Key header.
+func withIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+func withIfMatch(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, ifMatchCtx{}, tag)
+}
+
+// hasDeclaredLength tells whether the body of resp is expected to be as long as its Content-Length.
+// Responses to HEAD requests, and 204 and 304 responses, declare a length without ever carrying a body.
+func hasDeclaredLength(resp *http.Response) bool {
+	if resp.Request != nil && resp.Request.Method == http.MethodHead {
+		return false
 	}
-	return &responseData, nil
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return false
+	}
+	return resp.ContentLength >= 0 && len(resp.TransferEncoding) == 0
 }
 
 func (hac *httpAccountsClientImpl) init() {
+	if hac.apiVersion == "" {
+		hac.apiVersion = defaultAPIVersion
+	}
+	if hac.concurrency == 0 {
+		hac.concurrency = defaultConcurrency
+	}
+	if hac.maxResponseBytes == 0 {
+		hac.maxResponseBytes = defaultMaxResponseBytes
+	}
+	if hac.userAgent == "" {
+		hac.userAgent = defaultUserAgent
+	}
+	if hac.healthPath == "" {
+		hac.healthPath = defaultHealthPath
+	}
+	hac.serviceUrl = hac.host
+	if hac.basePath != "" {
+		hac.serviceUrl += "/" + hac.basePath
+	}
+	hac.serviceUrl += "/" + hac.apiVersion + "/" + resourcePath
+	// a body read or deserialized by the caller's own functions has to be read in full to be handed over
+	hac.streaming = hac.readInput == nil && hac.deserialize == nil
 	if hac.readInput == nil {
 		hac.readInput = io.ReadAll
 	}
 	if hac.doHttpGet == nil {
-		hac.doHttpGet = hac.client.Get
+		hac.doHttpGet = hac.get
+	}
+	if hac.doHttpHead == nil {
+		hac.doHttpHead = hac.head
 	}
 	if hac.doHttpPost == nil {
-		hac.doHttpPost = hac.client.Post
+		hac.doHttpPost = hac.post
 	}
 	if hac.createNewRequest == nil {
-		hac.createNewRequest = http.NewRequest
+		hac.createNewRequest = http.NewRequestWithContext
 	}
+	hac.wrapTransport()
 	if hac.doRequest == nil {
 		hac.doRequest = hac.client.Do
 	}
+	hac.logging = hac.logger != nil
+	if hac.logger == nil {
+		hac.logger = noopLogger{}
+	}
+	if hac.tracer == nil {
+		hac.tracer = noopTracer{}
+	}
+	if hac.metrics == nil {
+		hac.metrics = noopMetrics{}
+	}
+	if hac.clock == nil {
+		hac.clock = realClock{}
+	}
+	if hac.cache != nil {
+		hac.cache.maxStale = hac.maxStale
+	}
+	if hac.interceptResponse != nil {
+		hac.doRequest = hac.intercepted(hac.doRequest)
+	}
+	if hac.correlate {
+		hac.requestIDs, _ = hac.metrics.(RequestIDMetrics)
+	}
+	hac.doRequest = hac.observed(hac.doRequest)
+	if hac.limiter != nil {
+		hac.doRequest = hac.limited(hac.doRequest)
+	}
+	if hac.queue != nil {
+		hac.doRequest = hac.queued(hac.doRequest)
+	}
+	if hac.dryRun {
+		hac.doRequest = heldBack(hac.doRequest)
+	}
 	if hac.serialize == nil {
 		hac.serialize = json.Marshal
 	}
+	if hac.deserialize == nil {
+		hac.deserialize = json.Unmarshal
+	}
 }
 
-func unexpectedStatusCode(expected int, actual int, operation string, respPayload *[]byte) *HTTPError {
+// preparingError reports a request that couldn't be built, telling apart the ones whose base url couldn't be resolved.
+func preparingError(err error, method string) *HTTPError {
+	if httpErr := baseURLFailure(err); httpErr != nil {
+		return httpErr
+	}
 	return &HTTPError{
-		StatusCode: actual,
-		Message: fmt.Sprintf("Unexpected response code returned for %s operation, expected %d, got %d",
-			operation,
-			expected,
-			actual),
-		ResponsePayload: respPayload,
+		Cause:   err,
+		Message: fmt.Sprintf(msgPreparingRequest, method),
+		Kind:    KindNetwork,
 	}
 }
 
-type AccountsHttpClientFactory struct{}
-
-func (AccountsHttpClientFactory) MakeClient(baseUrl string) (HttpAccountsClient, error) {
-	if err := validateUrl(baseUrl); err != nil {
-		return nil, err
+// placingError reports a request that never got a response, telling apart the ones held back by the rate limiter,
+// the ones turned away by the request queue and the ones whose base url couldn't be resolved.
+func placingError(err error, method string) *HTTPError {
+	if httpErr := baseURLFailure(err); httpErr != nil {
+		return httpErr
+	}
+	if errors.Is(err, errDryRun) {
+		return &HTTPError{
+			Cause:   err,
+			Message: msgDryRun,
+			Kind:    KindValidation,
+		}
+	}
+	if errors.Is(err, errQueueFull) {
+		return &HTTPError{
+			Cause:   err,
+			Message: msgRequestQueueFull,
+			Kind:    KindNetwork,
+		}
+	}
+	var waitErr *rateLimitWaitError
+	if errors.As(err, &waitErr) {
+		return &HTTPError{
+			Cause:   waitErr.cause,
+			Message: msgRateLimitWaitCancelled,
+			Kind:    KindNetwork,
+		}
+	}
+	return &HTTPError{
+		Cause:   err,
+		Message: fmt.Sprintf(msgPlacingRequest, method),
+		Kind:    KindNetwork,
 	}
-	client := http.Client{}
-	httpClient := httpAccountsClientImpl{
-		host:   baseUrl,
-		client: &client}
-	httpClient.init()
-	return &httpClient, nil
 }
 
-func (AccountsHttpClientFactory) MakeTestClientWithInputReader(baseUrl string, readInput ReadInputStream) (HttpAccountsClient, error) {
-	if err := validateUrl(baseUrl); err != nil {
-		return nil, err
-	}
-	httpClient := httpAccountsClientImpl{host: baseUrl, client: &http.Client{}, readInput: readInput}
-	httpClient.init()
-	return &httpClient, nil
+// succeeded tells whether resp is the expected outcome of operation, i.e. whether it carries the status code
+// the accounts API documents for it or one accepted in addition to it with WithSuccessStatus.
+func (hac *httpAccountsClientImpl) succeeded(expected int, resp *http.Response, operation string) bool {
+	return resp.StatusCode == expected || (hac.successStatus != nil && hac.successStatus(operation, resp.StatusCode))
 }
 
-func (AccountsHttpClientFactory) MakeTestClientWithHttpGetter(baseUrl string, doHttpGet HttpGet) (HttpAccountsClient, error) {
-	if err := validateUrl(baseUrl); err != nil {
-		return nil, err
+func (hac *httpAccountsClientImpl) unexpectedStatusCode(expected int, resp *http.Response, operation string, respPayload *[]byte) *HTTPError {
+	wait, _ := retryAfter(resp, hac.clock.Now())
+	message := fmt.Sprintf(msgUnexpectedStatusCode, operation, expected, resp.StatusCode)
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		// only conditional requests get a 412, their condition being the account is as it was when fetched
+		message = msgModifiedSinceFetch
+	}
+	return &HTTPError{
+		StatusCode:      resp.StatusCode,
+		Status:          resp.Status,
+		Message:         message,
+		Kind:            kindOfStatusCode(resp.StatusCode),
+		ServerMessage:   serverMessage(resp, respPayload),
+		ResponsePayload: respPayload,
+		RetryAfter:      wait,
+		RequestID:       resp.Header.Get(requestID),
 	}
-	httpClient := httpAccountsClientImpl{host: baseUrl, client: &http.Client{}, doHttpGet: doHttpGet}
-	httpClient.init()
-	return &httpClient, nil
 }
 
-func (AccountsHttpClientFactory) MakeTestClientWithHttpPoster(baseUrl string, doHttpPost HttpPost) (HttpAccountsClient, error) {
-	if err := validateUrl(baseUrl); err != nil {
-		return nil, err
+// serverMessage extracts the error_message the server puts in json error responses,
+// e.g. {"error_message":"record ... does not exist"}.
+// Anything that can't be parsed as such yields an empty message.
+func serverMessage(resp *http.Response, respPayload *[]byte) string {
+	if respPayload == nil || !strings.HasPrefix(resp.Header.Get(contentType), jsonContentType) {
+		return ""
 	}
-	httpClient := httpAccountsClientImpl{host: baseUrl, client: &http.Client{}, doHttpPost: doHttpPost}
-	httpClient.init()
-	return &httpClient, nil
+	var errorResponse struct {
+		ErrorMessage string `json:"error_message"`
+	}
+	if err := json.Unmarshal(*respPayload, &errorResponse); err != nil {
+		return ""
+	}
+	return errorResponse.ErrorMessage
 }
 
-func (AccountsHttpClientFactory) MakeTestClientWithNewRequestCreator(baseUrl string, createNewRequest NewRequest) (HttpAccountsClient, error) {
-	if err := validateUrl(baseUrl); err != nil {
-		return nil, err
-	}
-	httpClient := httpAccountsClientImpl{host: baseUrl, client: &http.Client{}, createNewRequest: createNewRequest}
-	httpClient.init()
-	return &httpClient, nil
+type AccountsHttpClientFactory struct{}
+
+// MakeClient returns a client talking to the accounts service at baseUrl.
+// Without any options the client behaves exactly as a bare http.Client would,
+// see ClientOption for the available tweaks.
+func (AccountsHttpClientFactory) MakeClient(baseUrl string, opts ...ClientOption) (HttpAccountsClient, error) {
+	return makeClient(baseUrl, &httpAccountsClientImpl{}, opts)
 }
 
-func (AccountsHttpClientFactory) MakeTestClientWithRequestInvoker(baseUrl string, doRequest DoRequest) (HttpAccountsClient, error) {
-	if err := validateUrl(baseUrl); err != nil {
-		return nil, err
-	}
-	httpClient := httpAccountsClientImpl{host: baseUrl, client: &http.Client{}, doRequest: doRequest}
-	httpClient.init()
-	return &httpClient, nil
+func (AccountsHttpClientFactory) MakeTestClientWithInputReader(baseUrl string, readInput ReadInputStream, opts ...ClientOption) (HttpAccountsClient, error) {
+	return makeClient(baseUrl, &httpAccountsClientImpl{readInput: readInput}, opts)
+}
+
+func (AccountsHttpClientFactory) MakeTestClientWithHttpGetter(baseUrl string, doHttpGet HttpGet, opts ...ClientOption) (HttpAccountsClient, error) {
+	return makeClient(baseUrl, &httpAccountsClientImpl{doHttpGet: doHttpGet}, opts)
 }
 
-func (AccountsHttpClientFactory) MakeTestClientWithSerializer(baseUrl string, serialize Serialize) (HttpAccountsClient, error) {
+func (AccountsHttpClientFactory) MakeTestClientWithHttpHead(baseUrl string, doHttpHead HttpHead, opts ...ClientOption) (HttpAccountsClient, error) {
+	return makeClient(baseUrl, &httpAccountsClientImpl{doHttpHead: doHttpHead}, opts)
+}
+
+func (AccountsHttpClientFactory) MakeTestClientWithHttpPoster(baseUrl string, doHttpPost HttpPost, opts ...ClientOption) (HttpAccountsClient, error) {
+	return makeClient(baseUrl, &httpAccountsClientImpl{doHttpPost: doHttpPost}, opts)
+}
+
+func (AccountsHttpClientFactory) MakeTestClientWithNewRequestCreator(baseUrl string, createNewRequest NewRequest, opts ...ClientOption) (HttpAccountsClient, error) {
+	return makeClient(baseUrl, &httpAccountsClientImpl{createNewRequest: createNewRequest}, opts)
+}
+
+func (AccountsHttpClientFactory) MakeTestClientWithRequestInvoker(baseUrl string, doRequest DoRequest, opts ...ClientOption) (HttpAccountsClient, error) {
+	return makeClient(baseUrl, &httpAccountsClientImpl{doRequest: doRequest}, opts)
+}
+
+func (AccountsHttpClientFactory) MakeTestClientWithSerializer(baseUrl string, serialize Serialize, opts ...ClientOption) (HttpAccountsClient, error) {
+	return makeClient(baseUrl, &httpAccountsClientImpl{serialize: serialize}, opts)
+}
+
+func (AccountsHttpClientFactory) MakeTestClientWithDeserializer(baseUrl string, deserialize Deserialize, opts ...ClientOption) (HttpAccountsClient, error) {
+	return makeClient(baseUrl, &httpAccountsClientImpl{deserialize: deserialize}, opts)
+}
+
+func makeClient(baseUrl string, httpClient *httpAccountsClientImpl, opts []ClientOption) (HttpAccountsClient, error) {
 	if err := validateUrl(baseUrl); err != nil {
 		return nil, err
 	}
-	httpClient := httpAccountsClientImpl{host: baseUrl, client: &http.Client{}, serialize: serialize}
+	// paths get appended to the host with a slash of their own, a trailing one would double it
+	httpClient.host = strings.TrimRight(baseUrl, "/")
+	httpClient.client = &http.Client{}
+	httpClient.headers = http.Header{}
+	for _, opt := range opts {
+		if err := opt(httpClient); err != nil {
+			return nil, err
+		}
+	}
+	if err := httpClient.configureClient(); err != nil {
+		return nil, err
+	}
 	httpClient.init()
-	return &httpClient, nil
+	return httpClient, nil
 }
 
 func validateUrl(baseUrl string) error {
@@ -1,6 +1,7 @@
 package interview_accountapi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,8 +9,11 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestAccountsClientFactory_MakeHttpClient_NotValidUrl(t *testing.T) {
@@ -39,10 +43,11 @@ func TestAccountsClientFactory_MakeHttpClient(t *testing.T) {
 func TestFetch_IdIsNotUuid(t *testing.T) {
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient("https://abc.com")
-	account, httpErr := client.Fetch("blah")
+	account, httpErr := client.Fetch(context.Background(), "blah")
 
 	assertHttpError(t, httpErr, &HTTPError{
 		Message: "id must be a valid uuid",
+		Kind:    KindValidation,
 	})
 	assertAccountData(t, account, nil)
 }
@@ -57,13 +62,14 @@ func TestFetch_ErrorPlacingGet(t *testing.T) {
 
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeTestClientWithHttpGetter(server.URL,
-		func(path string) (*http.Response, error) {
+		func(ctx context.Context, path string) (*http.Response, error) {
 			return nil, err
 		})
-	account, httpErr := client.Fetch(id.String())
+	account, httpErr := client.Fetch(context.Background(), id.String())
 
 	assertHttpError(t, httpErr, &HTTPError{
-		Message: "Error placing a Get Http request",
+		Message: "Error placing Get Http request",
+		Kind:    KindNetwork,
 		Cause:   err,
 	})
 	assertAccountData(t, account, nil)
@@ -85,19 +91,98 @@ func TestFetch_StatusCodeNotOk(t *testing.T) {
 
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(server.URL)
-	account, httpErr := client.Fetch(id.String())
+	account, httpErr := client.Fetch(context.Background(), id.String())
 
 	emptyByteSlice := make([]byte, 0)
 
 	assertHttpError(t, httpErr, &HTTPError{
 		StatusCode:      400,
+		Status:          "400 Bad Request",
 		Message:         "Unexpected response code returned for Get operation, expected 200, got 400",
+		Kind:            KindValidation,
 		ResponsePayload: &emptyByteSlice,
 		Cause:           nil,
 	})
 	assertAccountData(t, account, nil)
 }
 
+func TestFetch_StatusCodeNotOkWithServerMessage(t *testing.T) {
+	id, _ := uuid.NewUUID()
+	payload := []byte(`{"error_message":"record ` + id.String() + ` does not exist"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.Fetch(context.Background(), id.String())
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      404,
+		Status:          "404 Not Found",
+		Message:         "Unexpected response code returned for Get operation, expected 200, got 404",
+		Kind:            KindNotFound,
+		ServerMessage:   "record " + id.String() + " does not exist",
+		ResponsePayload: &payload,
+	})
+	expectedError := "404 Not Found: Unexpected response code returned for Get operation, expected 200, got 404 : record " +
+		id.String() + " does not exist"
+	if httpErr.Error() != expectedError {
+		t.Errorf("HttpError detailed message doesn't match, expected=%s, got=%s", expectedError, httpErr.Error())
+	}
+	assertAccountData(t, account, nil)
+}
+
+func TestFetch_StatusCodeNotOkWithUnparsableJson(t *testing.T) {
+	payload := []byte(`{"error_message":`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Fetch(context.Background(), id.String())
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      500,
+		Status:          "500 Internal Server Error",
+		Message:         "Unexpected response code returned for Get operation, expected 200, got 500",
+		Kind:            KindServer,
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestFetch_StatusCodeNotOkWithNonJsonErrorMessage(t *testing.T) {
+	payload := []byte(`{"error_message":"not parsed, not json"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	id, _ := uuid.NewUUID()
+	_, httpErr := client.Fetch(context.Background(), id.String())
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      400,
+		Status:          "400 Bad Request",
+		Message:         "Unexpected response code returned for Get operation, expected 200, got 400",
+		Kind:            KindValidation,
+		ResponsePayload: &payload,
+	})
+}
+
 func TestFetch_ErrorProcessingResponseBody(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -115,15 +200,64 @@ func TestFetch_ErrorProcessingResponseBody(t *testing.T) {
 		})
 
 	id, _ := uuid.NewUUID()
-	account, httpErr := client.Fetch(id.String())
+	account, httpErr := client.Fetch(context.Background(), id.String())
 
 	assertHttpError(t, httpErr, &HTTPError{
 		Message: "Error processing response body",
+		Kind:    KindNetwork,
 		Cause:   err,
 	})
 	assertAccountData(t, account, nil)
 }
 
+func TestFetch_TruncatedResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "100") // promising more than we are going to send
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Fetch(context.Background(), id.String())
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "truncated response body",
+		Kind:    KindNetwork,
+		Cause:   io.ErrUnexpectedEOF,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestFetch_ReadLessThanContentLength(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"abc"}}`))
+	}))
+	defer server.Close()
+
+	payload := []byte("{}")
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithInputReader(server.URL,
+		func(reader io.Reader) ([]byte, error) {
+			return payload, nil
+		})
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Fetch(context.Background(), id.String())
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message:         "truncated response body",
+		Kind:            KindNetwork,
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+}
+
 func TestFetch_ContentTypeNotJson(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html")
@@ -134,19 +268,45 @@ func TestFetch_ContentTypeNotJson(t *testing.T) {
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(server.URL)
 	id, _ := uuid.NewUUID()
-	account, httpErr := client.Fetch(id.String())
+	account, httpErr := client.Fetch(context.Background(), id.String())
 
 	emptyByteSlice := make([]byte, 0)
 
 	assertHttpError(t, httpErr, &HTTPError{
 		StatusCode:      200,
-		Message:         "Unexpected  Content-Type, expecting application/json, got text/html",
+		Status:          "200 OK",
+		Message:         "Unexpected Content-Type, expecting application/json, got text/html",
+		Kind:            KindSerialization,
 		ResponsePayload: &emptyByteSlice,
 		Cause:           nil,
 	})
 	assertAccountData(t, account, nil)
 }
 
+func TestFetch_EmptyBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Fetch(context.Background(), id.String())
+
+	emptyByteSlice := make([]byte, 0)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      200,
+		Status:          "200 OK",
+		Message:         "empty body on successful response",
+		Kind:            KindServer,
+		ResponsePayload: &emptyByteSlice,
+	})
+	assertAccountData(t, account, nil)
+}
+
 func TestFetch_PayloadNotJsonDocument(t *testing.T) {
 	payload := []byte("blah")
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -159,11 +319,12 @@ func TestFetch_PayloadNotJsonDocument(t *testing.T) {
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(server.URL)
 	id, _ := uuid.NewUUID()
-	account, httpErr := client.Fetch(id.String())
+	account, httpErr := client.Fetch(context.Background(), id.String())
 
 	assertHttpError(t, httpErr, &HTTPError{
 		StatusCode:      0,
 		Message:         "Error deserializing json",
+		Kind:            KindSerialization,
 		Cause:           errors.New("invalid character 'b' looking for beginning of value"),
 		ResponsePayload: &payload,
 	})
@@ -182,11 +343,12 @@ func TestFetch_PayloadEmptyJsonObject(t *testing.T) {
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(server.URL)
 	id, _ := uuid.NewUUID()
-	account, httpErr := client.Fetch(id.String())
+	account, httpErr := client.Fetch(context.Background(), id.String())
 
 	assertHttpError(t, httpErr, &HTTPError{
 		StatusCode:      0,
 		Message:         "Got an empty object after deserialization, json payload was an empty object?",
+		Kind:            KindSerialization,
 		Cause:           nil,
 		ResponsePayload: &payload,
 	})
@@ -230,7 +392,7 @@ func TestFetch_HappyPath(t *testing.T) {
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(server.URL)
 	id, _ := uuid.NewUUID()
-	account, httpErr := client.Fetch(id.String())
+	account, httpErr := client.Fetch(context.Background(), id.String())
 
 	version := int64(32)
 	assertHttpError(t, httpErr, nil)
@@ -268,144 +430,563 @@ func TestFetch_HappyPath(t *testing.T) {
 	})
 }
 
-func TestDelete_IdIsNotUuid(t *testing.T) {
+func TestFetch_HappyPathWithExtendedAttributes(t *testing.T) {
+	payload := []byte(`{
+	"data":{
+		"id": "0d209d7f-d07a-4542-947f-5885fddddae2",
+		"organisation_id": "ba61483c-d5c5-4f50-ae81-6b8c039bea43",
+		"type": "accounts",
+		"version": 1,
+		"attributes": {
+			"acceptance_qualifier": "same_day",
+			"country": "GB",
+			"name": ["Jane Doe"],
+			"name_matching_status": "supported",
+			"processing_purpose": "salary",
+			"reference_mask": "############",
+			"user_defined_data": [{"key": "branch", "value": "Soho"}, {"key": "tier", "value": "gold"}],
+			"validation_type": "card"
+			}
+  		}
+	}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
 	clientFactory := AccountsHttpClientFactory{}
-	client, _ := clientFactory.MakeClient("https://abc.com")
-	httpErr := client.Delete("blah", 2)
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
 
-	assertHttpError(t, httpErr, &HTTPError{
-		Message: "id must be a valid uuid",
-	})
+	assertHttpError(t, httpErr, nil)
+
+	version := int64(1)
+	acceptanceQualifier := "same_day"
+	country := "GB"
+	nameMatchingStatus := "supported"
+	processingPurpose := "salary"
+	referenceMask := "############"
+	validationType := "card"
+
+	expected := &AccountData{
+		ID:             "0d209d7f-d07a-4542-947f-5885fddddae2",
+		OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43",
+		Type:           "accounts",
+		Version:        &version,
+		Attributes: &AccountAttributes{
+			AcceptanceQualifier: &acceptanceQualifier,
+			Country:             &country,
+			Name:                []string{"Jane Doe"},
+			NameMatchingStatus:  &nameMatchingStatus,
+			ProcessingPurpose:   &processingPurpose,
+			ReferenceMask:       &referenceMask,
+			UserDefinedData: []UserDefinedDatum{
+				{Key: "branch", Value: "Soho"},
+				{Key: "tier", Value: "gold"},
+			},
+			ValidationType: &validationType,
+		},
+	}
+	assertAccountData(t, account, expected)
+	if err := account.Validate(); err != nil {
+		t.Errorf("Expecting the extended attributes to survive a round trip, got %v", err)
+	}
 }
 
-func TestDelete_StatusCodeNotOk(t *testing.T) {
-	id, _ := uuid.NewUUID()
-	version := 2
+func TestFetch_ContextCancelled(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		expectedPathSuffix := fmt.Sprintf("/%s/%s?version=%d", servicePath, id, version)
-		if !strings.HasSuffix(r.URL.String(), expectedPathSuffix) {
-			t.Errorf("invoked path doesn't match with the expected suffix")
-		}
-		if r.Method != "DELETE" {
-			t.Errorf("unexpected http method, got=%s, expected=DELETE", r.Method)
-		}
-		w.WriteHeader(http.StatusBadRequest)
+		t.Errorf("request with a cancelled context must not reach the server")
 	}))
 	defer server.Close()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(server.URL)
-	httpErr := client.Delete(id.String(), 2)
-
-	emptyByteSlice := make([]byte, 0)
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Fetch(ctx, id.String())
 
-	assertHttpError(t, httpErr, &HTTPError{
-		StatusCode:      400,
-		Message:         "Unexpected response code returned for Delete operation, expected 204, got 400",
-		ResponsePayload: &emptyByteSlice,
-		Cause:           nil,
-	})
+	if httpErr == nil || !errors.Is(httpErr.Cause, context.Canceled) {
+		t.Fatalf("Expecting http error caused by context.Canceled, got=%v", httpErr)
+	}
+	if httpErr.Message != "Error placing Get Http request" {
+		t.Errorf("HttpError message doesn't match, got=%s", httpErr.Message)
+	}
+	assertAccountData(t, account, nil)
 }
 
-func TestDelete_CannotCreateNewRequest(t *testing.T) {
-	id, _ := uuid.NewUUID()
+func TestFetch_ContextDeadlineExceeded(t *testing.T) {
+	unblock := make(chan struct{})
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
 	}))
 	defer server.Close()
+	defer close(unblock)
 
-	err := errors.New("unable to create new request")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
 
 	clientFactory := AccountsHttpClientFactory{}
-	client, _ := clientFactory.MakeTestClientWithNewRequestCreator(server.URL,
-		func(method string, path string, reader io.Reader) (*http.Request, error) {
-			return nil, err
-		})
-	httpErr := client.Delete(id.String(), 2)
+	client, _ := clientFactory.MakeClient(server.URL)
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Fetch(ctx, id.String())
 
-	assertHttpError(t, httpErr, &HTTPError{
-		Message: "Error preparing Delete Http request",
-		Cause:   err,
-	})
+	if httpErr == nil || !errors.Is(httpErr.Cause, context.DeadlineExceeded) {
+		t.Fatalf("Expecting http error caused by context.DeadlineExceeded, got=%v", httpErr)
+	}
+	assertAccountData(t, account, nil)
 }
 
-func TestDelete_CannotPlaceRequest(t *testing.T) {
-	id, _ := uuid.NewUUID()
+func TestFetchWithParams(t *testing.T) {
+	var rawQuery string
+	var fields []string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		fields = r.URL.Query()["fields[accounts]"]
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
 	}))
 	defer server.Close()
 
-	err := errors.New("unable to place request")
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.FetchWithParams(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2",
+		url.Values{"fields[accounts]": []string{"iban,name & bic"}})
+
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+	if rawQuery != "fields%5Baccounts%5D=iban%2Cname+%26+bic" {
+		t.Errorf("Expecting an encoded query string, got=%s", rawQuery)
+	}
+	if len(fields) != 1 || fields[0] != "iban,name & bic" {
+		t.Errorf("Expecting the params to reach the server intact, got=%v", fields)
+	}
+}
 
+func TestFetchWithParams_IdIsNotUuid(t *testing.T) {
 	clientFactory := AccountsHttpClientFactory{}
-	client, _ := clientFactory.MakeTestClientWithRequestInvoker(server.URL,
-		func(request *http.Request) (*http.Response, error) {
-			return nil, err
-		})
-	httpErr := client.Delete(id.String(), 2)
+	client, _ := clientFactory.MakeClient("https://abc.com")
+	account, httpErr := client.FetchWithParams(context.Background(), "blah", url.Values{"fields[accounts]": []string{"iban"}})
 
 	assertHttpError(t, httpErr, &HTTPError{
-		Message: "Error placing Delete Http request",
-		Cause:   err,
+		Message: "id must be a valid uuid",
+		Kind:    KindValidation,
 	})
+	assertAccountData(t, account, nil)
 }
 
-func TestDelete_PayloadNotJsonDocument(t *testing.T) {
-	payload := []byte("blah") // we are not deserializing payload on delete, so let's check if it is added on non-204 code
+func TestFetchWithResponse_HappyPath(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusBadRequest) // if status isn't 204, we attach payload to the error
-		w.Write(payload)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req-123")
+		w.Header().Set("ETag", `"v7"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
 	}))
 	defer server.Close()
 
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(server.URL)
 	id, _ := uuid.NewUUID()
-	httpErr := client.Delete(id.String(), 2)
+	account, header, httpErr := client.FetchWithResponse(context.Background(), id.String())
 
-	assertHttpError(t, httpErr, &HTTPError{
-		StatusCode:      400,
-		Message:         "Unexpected response code returned for Delete operation, expected 204, got 400",
-		ResponsePayload: &payload,
-		Cause:           nil,
-	})
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+
+	if header.Get("X-Request-Id") != "req-123" {
+		t.Errorf("X-Request-Id header doesn't match, got=%s", header.Get("X-Request-Id"))
+	}
+	if header.Get("ETag") != `"v7"` {
+		t.Errorf("ETag header doesn't match, got=%s", header.Get("ETag"))
+	}
 }
 
-func TestDelete_ErrorProcessingResponseBody(t *testing.T) {
+func TestFetchWithResponse_NoHeadersOnError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusBadRequest)
+		w.Header().Set("X-Request-Id", "req-123")
+		w.WriteHeader(http.StatusNotFound)
 	}))
 	defer server.Close()
 
 	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	id, _ := uuid.NewUUID()
+	account, header, httpErr := client.FetchWithResponse(context.Background(), id.String())
 
-	err := errors.New("unable to finish reading input stream")
+	emptyByteSlice := make([]byte, 0)
 
-	client, _ := clientFactory.MakeTestClientWithInputReader(server.URL,
-		func(reader io.Reader) ([]byte, error) {
-			return nil, err
-		})
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      404,
+		Status:          "404 Not Found",
+		Message:         "Unexpected response code returned for Get operation, expected 200, got 404",
+		Kind:            KindNotFound,
+		RequestID:       "req-123",
+		ResponsePayload: &emptyByteSlice,
+	})
+	assertAccountData(t, account, nil)
+	if header != nil {
+		t.Errorf("Expecting headers to be nil")
+	}
+}
+
+func TestUpdate_IdIsNotUuid(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+	account, httpErr := client.Update(context.Background(), "blah", 0, &AccountData{})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "id must be a valid uuid",
+		Kind:    KindValidation,
+	})
+	assertAccountData(t, account, nil)
+}
 
+func TestUpdate_NilAccount(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
 	id, _ := uuid.NewUUID()
-	httpErr := client.Delete(id.String(), 2)
+	account, httpErr := client.Update(context.Background(), id.String(), 0, nil)
 
 	assertHttpError(t, httpErr, &HTTPError{
-		Message: "Error processing response body",
-		Cause:   err,
+		Message: "account must not be nil",
+		Kind:    KindValidation,
 	})
+	assertAccountData(t, account, nil)
 }
 
-func TestDelete_HappyPath(t *testing.T) {
+func TestUpdate_NegativeVersion(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Update(context.Background(), id.String(), -1, &AccountData{})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "version must not be negative",
+		Kind:    KindValidation,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestUpdate_VersionConflict(t *testing.T) {
+	payload := []byte(`{"error_message":"invalid version"}`)
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusNoContent)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		w.Write(payload)
 	}))
 	defer server.Close()
 
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(server.URL)
 	id, _ := uuid.NewUUID()
-	httpErr := client.Delete(id.String(), 3)
+	account, httpErr := client.Update(context.Background(), id.String(), 1, &AccountData{})
 
-	assertHttpError(t, httpErr, nil)
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      409,
+		Status:          "409 Conflict",
+		Message:         "Unexpected response code returned for Patch operation, expected 200, got 409",
+		Kind:            KindConflict,
+		ServerMessage:   "invalid version",
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestUpdate_CannotPlaceRequest(t *testing.T) {
+	err := errors.New("unable to place request")
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithRequestInvoker("https://abc.com",
+		func(request *http.Request) (*http.Response, error) {
+			return nil, err
+		})
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Update(context.Background(), id.String(), 1, &AccountData{})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "Error placing Patch Http request",
+		Kind:    KindNetwork,
+		Cause:   err,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestUpdate_HappyPath(t *testing.T) {
+	id, _ := uuid.NewUUID()
+	status := "confirmed"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("unexpected http method, got=%s, expected=PATCH", r.Method)
+		}
+		if r.URL.Path != fmt.Sprintf("/%s/%s", servicePath, id) {
+			t.Errorf("unexpected path, got=%s", r.URL.Path)
+		}
+		if r.Header.Get(contentType) != jsonContentType {
+			t.Errorf("unexpected content type, got=%s, expected=%s", r.Header.Get(contentType), jsonContentType)
+		}
+		requestBody, _ := io.ReadAll(r.Body)
+		var requestEnvelope *Envelope[AccountData]
+		json.Unmarshal(requestBody, &requestEnvelope)
+
+		version := int64(2)
+		assertAccountData(t, requestEnvelope.Data, &AccountData{
+			ID:         id.String(),
+			Version:    &version,
+			Attributes: &AccountAttributes{Status: &status},
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		// the server bumps the version on every update
+		w.Write([]byte(strings.Replace(string(requestBody), `"version":2`, `"version":3`, 1)))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	request := &AccountData{Attributes: &AccountAttributes{Status: &status}}
+	account, httpErr := client.Update(context.Background(), id.String(), 2, request)
+
+	version := int64(3)
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{
+		ID:         id.String(),
+		Version:    &version,
+		Attributes: &AccountAttributes{Status: &status},
+	})
+	if request.Version != nil || request.ID != "" {
+		t.Errorf("Update must not modify the caller's account")
+	}
+}
+
+func TestDelete_IdIsNotUuid(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+	httpErr := client.Delete(context.Background(), "blah", 2)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "id must be a valid uuid",
+		Kind:    KindValidation,
+	})
+}
+
+func TestDelete_NegativeVersion(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+	id, _ := uuid.NewUUID()
+	httpErr := client.Delete(context.Background(), id.String(), -1)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "version must not be negative",
+		Kind:    KindValidation,
+	})
+}
+
+func TestDelete_StatusCodeNotOk(t *testing.T) {
+	id, _ := uuid.NewUUID()
+	version := 2
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expectedPathSuffix := fmt.Sprintf("/%s/%s?version=%d", servicePath, id, version)
+		if !strings.HasSuffix(r.URL.String(), expectedPathSuffix) {
+			t.Errorf("invoked path doesn't match with the expected suffix")
+		}
+		if r.Method != "DELETE" {
+			t.Errorf("unexpected http method, got=%s, expected=DELETE", r.Method)
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	httpErr := client.Delete(context.Background(), id.String(), 2)
+
+	emptyByteSlice := make([]byte, 0)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      400,
+		Status:          "400 Bad Request",
+		Message:         "Unexpected response code returned for Delete operation, expected 204, got 400",
+		Kind:            KindValidation,
+		ResponsePayload: &emptyByteSlice,
+		Cause:           nil,
+	})
+}
+
+func TestDelete_CannotCreateNewRequest(t *testing.T) {
+	id, _ := uuid.NewUUID()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	}))
+	defer server.Close()
+
+	err := errors.New("unable to create new request")
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithNewRequestCreator(server.URL,
+		func(ctx context.Context, method string, path string, reader io.Reader) (*http.Request, error) {
+			return nil, err
+		})
+	httpErr := client.Delete(context.Background(), id.String(), 2)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "Error preparing Delete Http request",
+		Kind:    KindNetwork,
+		Cause:   err,
+	})
+}
+
+func TestDelete_CannotPlaceRequest(t *testing.T) {
+	id, _ := uuid.NewUUID()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	}))
+	defer server.Close()
+
+	err := errors.New("unable to place request")
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithRequestInvoker(server.URL,
+		func(request *http.Request) (*http.Response, error) {
+			return nil, err
+		})
+	httpErr := client.Delete(context.Background(), id.String(), 2)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "Error placing Delete Http request",
+		Kind:    KindNetwork,
+		Cause:   err,
+	})
+}
+
+func TestDelete_PayloadNotJsonDocument(t *testing.T) {
+	payload := []byte("blah") // we are not deserializing payload on delete, so let's check if it is added on non-204 code
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest) // if status isn't 204, we attach payload to the error
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	id, _ := uuid.NewUUID()
+	httpErr := client.Delete(context.Background(), id.String(), 2)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      400,
+		Status:          "400 Bad Request",
+		Message:         "Unexpected response code returned for Delete operation, expected 204, got 400",
+		Kind:            KindValidation,
+		ResponsePayload: &payload,
+		Cause:           nil,
+	})
+}
+
+func TestDelete_ErrorProcessingResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+
+	err := errors.New("unable to finish reading input stream")
+
+	client, _ := clientFactory.MakeTestClientWithInputReader(server.URL,
+		func(reader io.Reader) ([]byte, error) {
+			return nil, err
+		})
+
+	id, _ := uuid.NewUUID()
+	httpErr := client.Delete(context.Background(), id.String(), 2)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "Error processing response body",
+		Kind:    KindNetwork,
+		Cause:   err,
+	})
+}
+
+func TestDelete_HappyPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	id, _ := uuid.NewUUID()
+	httpErr := client.Delete(context.Background(), id.String(), 3)
+
+	assertHttpError(t, httpErr, nil)
+}
+
+// drainTrackingBody tells whether it was read to the end and closed.
+type drainTrackingBody struct {
+	io.Reader
+	drained bool
+	closed  bool
+}
+
+func (b *drainTrackingBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if err == io.EOF {
+		b.drained = true
+	}
+	return n, err
+}
+
+func (b *drainTrackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestDelete_NoContentWithBody(t *testing.T) {
+	// net/http servers refuse to write a body along with a 204, a misbehaving server is faked by the invoker instead
+	body := &drainTrackingBody{Reader: strings.NewReader(`{"unexpected":"body"}`)}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithRequestInvoker("http://localhost:8080",
+		func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode:    http.StatusNoContent,
+				Status:        "204 No Content",
+				Header:        http.Header{},
+				Body:          body,
+				ContentLength: -1,
+				Request:       request,
+			}, nil
+		})
+	id, _ := uuid.NewUUID()
+	httpErr := client.Delete(context.Background(), id.String(), 3)
+
+	assertHttpError(t, httpErr, nil)
+	if !body.drained || !body.closed {
+		t.Errorf("Expecting the body to be drained and closed, drained=%t closed=%t", body.drained, body.closed)
+	}
+}
+
+func TestDelete_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request with a cancelled context must not reach the server")
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	id, _ := uuid.NewUUID()
+	httpErr := client.Delete(ctx, id.String(), 0)
+
+	if httpErr == nil || !errors.Is(httpErr.Cause, context.Canceled) {
+		t.Fatalf("Expecting http error caused by context.Canceled, got=%v", httpErr)
+	}
+	if httpErr.Message != "Error placing Delete Http request" {
+		t.Errorf("HttpError message doesn't match, got=%s", httpErr.Message)
+	}
 }
 
 func TestCreate_StatusCodeNotCreated(t *testing.T) {
@@ -447,13 +1028,15 @@ func TestCreate_StatusCodeNotCreated(t *testing.T) {
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(server.URL)
 
-	account, httpErr := client.Create(accountData)
+	account, httpErr := client.Create(context.Background(), accountData)
 
 	emptyByteSlice := make([]byte, 0)
 
 	assertHttpError(t, httpErr, &HTTPError{
 		StatusCode:      400,
+		Status:          "400 Bad Request",
 		Message:         "Unexpected response code returned for Post operation, expected 201, got 400",
+		Kind:            KindValidation,
 		Cause:           nil,
 		ResponsePayload: &emptyByteSlice,
 	})
@@ -462,6 +1045,7 @@ func TestCreate_StatusCodeNotCreated(t *testing.T) {
 
 func TestCreate_ErrorDeserializingResponseOnEmptyResponse(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
 	}))
 	defer server.Close()
@@ -470,12 +1054,13 @@ func TestCreate_ErrorDeserializingResponseOnEmptyResponse(t *testing.T) {
 
 	client, _ := clientFactory.MakeClient(server.URL)
 
-	account, httpErr := client.Create(&AccountData{})
+	account, httpErr := client.Create(context.Background(), &AccountData{})
 
 	emptyByteSlice := make([]byte, 0)
 
 	assertHttpError(t, httpErr, &HTTPError{
 		Message:         "Error deserializing json",
+		Kind:            KindSerialization,
 		ResponsePayload: &emptyByteSlice,
 		Cause:           errors.New("unexpected end of JSON input"),
 	})
@@ -486,6 +1071,7 @@ func TestCreate_ErrorDeserializingResponseOnEmptyResponse(t *testing.T) {
 func TestCreate_ErrorDeserializingResponseNotJsonDocument(t *testing.T) {
 	payload := []byte("blah")
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
 		w.Write(payload)
 	}))
@@ -495,10 +1081,11 @@ func TestCreate_ErrorDeserializingResponseNotJsonDocument(t *testing.T) {
 
 	client, _ := clientFactory.MakeClient(server.URL)
 
-	account, httpErr := client.Create(&AccountData{})
+	account, httpErr := client.Create(context.Background(), &AccountData{})
 
 	assertHttpError(t, httpErr, &HTTPError{
 		Message:         "Error deserializing json",
+		Kind:            KindSerialization,
 		ResponsePayload: &payload,
 		Cause:           errors.New("invalid character 'b' looking for beginning of value"),
 	})
@@ -509,6 +1096,7 @@ func TestCreate_ErrorDeserializingResponseNotJsonDocument(t *testing.T) {
 func TestCreate_ResponsePayloadEmptyJsonDocument(t *testing.T) {
 	payload := []byte("{}")
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
 		w.Write(payload)
 	}))
@@ -518,10 +1106,11 @@ func TestCreate_ResponsePayloadEmptyJsonDocument(t *testing.T) {
 
 	client, _ := clientFactory.MakeClient(server.URL)
 
-	account, httpErr := client.Create(&AccountData{})
+	account, httpErr := client.Create(context.Background(), &AccountData{})
 
 	assertHttpError(t, httpErr, &HTTPError{
 		Message:         "Got an empty object after deserialization, json payload was an empty object?",
+		Kind:            KindSerialization,
 		ResponsePayload: &payload,
 		Cause:           nil,
 	})
@@ -529,6 +1118,29 @@ func TestCreate_ResponsePayloadEmptyJsonDocument(t *testing.T) {
 	assertAccountData(t, account, nil)
 }
 
+func TestCreate_ContentTypeNotJson(t *testing.T) {
+	payload := []byte("<html><body>Created</body></html>")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.Create(context.Background(), &AccountData{})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      201,
+		Status:          "201 Created",
+		Message:         "Unexpected Content-Type, expecting application/json, got text/html",
+		Kind:            KindSerialization,
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+}
+
 func TestCreate_ErrorSerializingRequestPayload(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 	}))
@@ -543,10 +1155,11 @@ func TestCreate_ErrorSerializingRequestPayload(t *testing.T) {
 			return nil, err
 		})
 
-	account, httpErr := client.Create(&AccountData{})
+	account, httpErr := client.Create(context.Background(), &AccountData{})
 
 	assertHttpError(t, httpErr, &HTTPError{
 		Message: "Unable to serialize payload",
+		Kind:    KindSerialization,
 		Cause:   err,
 	})
 
@@ -563,14 +1176,15 @@ func TestCreate_ErrorPlacingPostRequest(t *testing.T) {
 	err := errors.New("cannot post")
 
 	client, _ := clientFactory.MakeTestClientWithHttpPoster(server.URL,
-		func(url, contentType string, body io.Reader) (*http.Response, error) {
+		func(ctx context.Context, url, contentType string, body io.Reader) (*http.Response, error) {
 			return nil, err
 		})
 
-	account, httpErr := client.Create(&AccountData{})
+	account, httpErr := client.Create(context.Background(), &AccountData{})
 
 	assertHttpError(t, httpErr, &HTTPError{
-		Message: "Error placing a Post Http request",
+		Message: "Error placing Post Http request",
+		Kind:    KindNetwork,
 		Cause:   err,
 	})
 
@@ -593,10 +1207,11 @@ func TestCreate_ErrorProcessingResponseBody(t *testing.T) {
 			return nil, err
 		})
 
-	account, httpErr := client.Create(&AccountData{})
+	account, httpErr := client.Create(context.Background(), &AccountData{})
 
 	assertHttpError(t, httpErr, &HTTPError{
 		Message: "Error processing response body",
+		Kind:    KindNetwork,
 		Cause:   err,
 	})
 	assertAccountData(t, account, nil)
@@ -647,8 +1262,1242 @@ func TestCreate_HappyPath(t *testing.T) {
 	clientFactory := AccountsHttpClientFactory{}
 	client, _ := clientFactory.MakeClient(server.URL)
 
-	responseAccount, httpErr := client.Create(requestAccount)
+	responseAccount, httpErr := client.Create(context.Background(), requestAccount)
 
 	assertHttpError(t, httpErr, nil)
 	assertAccountData(t, responseAccount, requestAccount)
 }
+
+func TestCreate_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request with a cancelled context must not reach the server")
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.Create(ctx, &AccountData{})
+
+	if httpErr == nil || !errors.Is(httpErr.Cause, context.Canceled) {
+		t.Fatalf("Expecting http error caused by context.Canceled, got=%v", httpErr)
+	}
+	if httpErr.Message != "Error placing Post Http request" {
+		t.Errorf("HttpError message doesn't match, got=%s", httpErr.Message)
+	}
+	assertAccountData(t, account, nil)
+}
+
+func TestCreateWithIdempotencyKey_SendsKey(t *testing.T) {
+	key := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = r.Header.Get("Idempotency-Key")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	_, httpErr := client.CreateWithIdempotencyKey(context.Background(), "create-42", &AccountData{})
+
+	assertHttpError(t, httpErr, nil)
+	if key != "create-42" {
+		t.Errorf("Expecting Idempotency-Key create-42, got=%s", key)
+	}
+}
+
+func TestCreateWithIdempotencyKey_EmptyKey(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+	account, httpErr := client.CreateWithIdempotencyKey(context.Background(), "", &AccountData{})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "idempotency key must not be empty",
+		Kind:    KindValidation,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestCreate_NoIdempotencyKeyWithoutRetry(t *testing.T) {
+	keys := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys += len(r.Header.Values("Idempotency-Key"))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	client.Create(context.Background(), &AccountData{})
+
+	if keys != 0 {
+		t.Errorf("Expecting no Idempotency-Key header")
+	}
+}
+
+func TestList_PageSizeOutOfRange(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+
+	for _, pageSize := range []int{0, -1, 101} {
+		accounts, links, httpErr := client.List(context.Background(), 0, pageSize)
+
+		assertHttpError(t, httpErr, &HTTPError{
+			Message: "page size must be between 1 and 100",
+			Kind:    KindValidation,
+		})
+		if accounts != nil || links != nil {
+			t.Errorf("Expecting accounts and links to be nil for page size %d", pageSize)
+		}
+	}
+}
+
+func TestList_NegativePageNumber(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+	accounts, _, httpErr := client.List(context.Background(), -1, 10)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "page number must not be negative",
+		Kind:    KindValidation,
+	})
+	if accounts != nil {
+		t.Errorf("Expecting accounts to be nil")
+	}
+}
+
+func TestList_StatusCodeNotOk(t *testing.T) {
+	payload := []byte(`{"error_message":"boom"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	accounts, links, httpErr := client.List(context.Background(), 0, 10)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      500,
+		Status:          "500 Internal Server Error",
+		Message:         "Unexpected response code returned for List operation, expected 200, got 500",
+		Kind:            KindServer,
+		ResponsePayload: &payload,
+	})
+	if accounts != nil || links != nil {
+		t.Errorf("Expecting accounts and links to be nil")
+	}
+}
+
+func TestList_PayloadNotJsonDocument(t *testing.T) {
+	payload := []byte("blah")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	accounts, _, httpErr := client.List(context.Background(), 0, 10)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message:         "Error deserializing json",
+		Kind:            KindSerialization,
+		Cause:           errors.New("invalid character 'b' looking for beginning of value"),
+		ResponsePayload: &payload,
+	})
+	if accounts != nil {
+		t.Errorf("Expecting accounts to be nil")
+	}
+}
+
+func TestList_EmptyPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":[],"links":{"self":"/v1/organisation/accounts?page[number]=5&page[size]=10"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	accounts, links, httpErr := client.List(context.Background(), 5, 10)
+
+	assertHttpError(t, httpErr, nil)
+	if accounts == nil || len(accounts) != 0 {
+		t.Errorf("Expecting an empty, non-nil accounts slice")
+	}
+	if links.HasNext() {
+		t.Errorf("Expecting no next page")
+	}
+}
+
+func TestList_HappyPath(t *testing.T) {
+	payload := []byte(`{
+	"data":[
+		{"id": "0d209d7f-d07a-4542-947f-5885fddddae2", "type": "accounts", "version": 0,
+			"attributes": {"bank_id": "400300", "country": "GB"}},
+		{"id": "1c3b6e4a-3c1f-4a4a-9a8e-2c4a0fd7c1a1", "type": "accounts", "version": 2}
+	],
+	"links": {
+		"first": "/v1/organisation/accounts?page%5Bnumber%5D=first&page%5Bsize%5D=2",
+		"last": "/v1/organisation/accounts?page%5Bnumber%5D=last&page%5Bsize%5D=2",
+		"next": "/v1/organisation/accounts?page%5Bnumber%5D=2&page%5Bsize%5D=2",
+		"prev": "/v1/organisation/accounts?page%5Bnumber%5D=0&page%5Bsize%5D=2",
+		"self": "/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=2"
+	}
+	}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+servicePath {
+			t.Errorf("unexpected path, got=%s", r.URL.Path)
+		}
+		if r.Method != "GET" {
+			t.Errorf("unexpected http method, got=%s, expected=GET", r.Method)
+		}
+		if r.URL.Query().Get("page[number]") != "1" || r.URL.Query().Get("page[size]") != "2" {
+			t.Errorf("unexpected paging query, got=%s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	accounts, links, httpErr := client.List(context.Background(), 1, 2)
+
+	assertHttpError(t, httpErr, nil)
+
+	if len(accounts) != 2 {
+		t.Fatalf("Expecting 2 accounts, got=%d", len(accounts))
+	}
+
+	firstVersion := int64(0)
+	secondVersion := int64(2)
+	country := "GB"
+	assertAccountData(t, accounts[0], &AccountData{
+		ID:         "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Type:       "accounts",
+		Version:    &firstVersion,
+		Attributes: &AccountAttributes{BankID: "400300", Country: &country},
+	})
+	assertAccountData(t, accounts[1], &AccountData{
+		ID:      "1c3b6e4a-3c1f-4a4a-9a8e-2c4a0fd7c1a1",
+		Type:    "accounts",
+		Version: &secondVersion,
+	})
+
+	if !links.HasNext() {
+		t.Errorf("Expecting a next page")
+	}
+	if links.Next != "/v1/organisation/accounts?page%5Bnumber%5D=2&page%5Bsize%5D=2" {
+		t.Errorf("Unexpected next link, got=%s", links.Next)
+	}
+}
+
+func TestWarmup_NotPositive(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+	httpErr := client.Warmup(context.Background(), 0)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "number of connections to warm up must be positive",
+		Kind:    KindValidation,
+	})
+}
+
+func TestWarmup_HostUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	httpErr := client.Warmup(context.Background(), 2)
+
+	if httpErr == nil || httpErr.Cause == nil {
+		t.Fatalf("Expecting warmup to fail with a cause")
+	}
+	if httpErr.Message != "error warming up connections" {
+		t.Errorf("HttpError message doesn't match, got=%s", httpErr.Message)
+	}
+}
+
+func TestWarmup_SubsequentRequestReusesConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+
+	httpErr := client.Warmup(context.Background(), 2)
+	assertHttpError(t, httpErr, nil)
+
+	reused := false
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			reused = info.Reused
+		},
+	})
+
+	id, _ := uuid.NewUUID()
+	httpErr = client.Delete(ctx, id.String(), 0)
+	assertHttpError(t, httpErr, nil)
+
+	if !reused {
+		t.Errorf("Expecting the request to reuse a warmed up connection")
+	}
+}
+
+func TestListAll_FollowsNextLinks(t *testing.T) {
+	pages := map[string]string{
+		"0": `{"data":[{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"},{"id":"1c3b6e4a-3c1f-4a4a-9a8e-2c4a0fd7c1a1"}],
+			"links":{"next":"/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=100"}}`,
+		"1": `{"data":[{"id":"6b7e2a4c-8e0f-4b1d-9d3a-5f2c7e1a9b40"}],
+			"links":{"next":"/v1/organisation/accounts?page%5Bnumber%5D=2&page%5Bsize%5D=100"}}`,
+		"2": `{"data":[{"id":"a4f1c2d3-5e6b-4c7d-8e9f-0a1b2c3d4e5f"}],"links":{"next":""}}`,
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("page[size]") != "100" {
+			t.Errorf("unexpected page size, got=%s", r.URL.Query().Get("page[size]"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(pages[r.URL.Query().Get("page[number]")]))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	accounts, httpErr := client.ListAll(context.Background())
+
+	assertHttpError(t, httpErr, nil)
+	if requests != 3 {
+		t.Errorf("Expecting 3 page requests, got=%d", requests)
+	}
+
+	expectedIds := []string{
+		"0d209d7f-d07a-4542-947f-5885fddddae2",
+		"1c3b6e4a-3c1f-4a4a-9a8e-2c4a0fd7c1a1",
+		"6b7e2a4c-8e0f-4b1d-9d3a-5f2c7e1a9b40",
+		"a4f1c2d3-5e6b-4c7d-8e9f-0a1b2c3d4e5f",
+	}
+	if len(accounts) != len(expectedIds) {
+		t.Fatalf("Expecting %d accounts, got=%d", len(expectedIds), len(accounts))
+	}
+	for i, id := range expectedIds {
+		if accounts[i].ID != id {
+			t.Errorf("Account %d id doesn't match, expected=%s, got=%s", i, id, accounts[i].ID)
+		}
+	}
+}
+
+func TestListAll_PaginationCycle(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		// the server keeps pointing at the same page over and over again
+		w.Write([]byte(`{"data":[{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}],
+			"links":{"next":"/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=100"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	accounts, httpErr := client.ListAll(context.Background())
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "pagination cycle detected",
+		Kind:    KindServer,
+	})
+	if accounts != nil {
+		t.Errorf("Expecting accounts to be nil")
+	}
+	if requests != 2 {
+		t.Errorf("Expecting pagination to stop after the repeated link, got %d requests", requests)
+	}
+}
+
+func TestListAll_PageFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page[number]") == "1" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":[{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}],
+			"links":{"next":"/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=100"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	accounts, httpErr := client.ListAll(context.Background())
+
+	emptyByteSlice := make([]byte, 0)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      503,
+		Status:          "503 Service Unavailable",
+		Message:         "Unexpected response code returned for List operation, expected 200, got 503",
+		Kind:            KindServer,
+		ResponsePayload: &emptyByteSlice,
+	})
+	if accounts != nil {
+		t.Errorf("Expecting accounts to be nil")
+	}
+}
+
+func TestCreateFromReader_NilReader(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+	account, httpErr := client.CreateFromReader(context.Background(), nil)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "reader must not be nil",
+		Kind:    KindValidation,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestCreateFromReader_HappyPath(t *testing.T) {
+	requestPayload := `{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","type":"accounts",
+		"attributes":{"country":"GB","bank_id":"400300"}}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("unexpected http method, got=%s, expected=POST", r.Method)
+		}
+		if r.Header.Get(contentType) != jsonContentType {
+			t.Errorf("unexpected content type, got=%s, expected=%s", r.Header.Get(contentType), jsonContentType)
+		}
+		requestBody, _ := io.ReadAll(r.Body)
+		if string(requestBody) != requestPayload {
+			t.Errorf("request body must be streamed verbatim, got=%s", requestBody)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(requestBody)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.CreateFromReader(context.Background(), strings.NewReader(requestPayload))
+
+	country := "GB"
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{
+		ID:         "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Type:       "accounts",
+		Attributes: &AccountAttributes{Country: &country, BankID: "400300"},
+	})
+}
+
+func TestCreateFromReader_StatusCodeNotCreated(t *testing.T) {
+	payload := []byte(`{"error_message":"validation failure"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.CreateFromReader(context.Background(), strings.NewReader(`{"data":{}}`))
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      400,
+		Status:          "400 Bad Request",
+		Message:         "Unexpected response code returned for Post operation, expected 201, got 400",
+		Kind:            KindValidation,
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestHealthCheck_Healthy(t *testing.T) {
+	path := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"up"}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	httpErr := client.HealthCheck(context.Background())
+
+	assertHttpError(t, httpErr, nil)
+	if path != "/v1/health" {
+		t.Errorf("Expecting path=/v1/health, got=%s", path)
+	}
+}
+
+func TestHealthCheck_Unhealthy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	httpErr := client.HealthCheck(context.Background())
+
+	emptyPayload := make([]byte, 0)
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      503,
+		Status:          "503 Service Unavailable",
+		Message:         "Unexpected response code returned for HealthCheck operation, expected 200, got 503",
+		Kind:            KindServer,
+		ResponsePayload: &emptyPayload,
+	})
+}
+
+func TestHealthCheck_ErrorPlacingRequest(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithHttpGetter("http://localhost:8080", func(context.Context, string) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	httpErr := client.HealthCheck(context.Background())
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:   errors.New("connection refused"),
+		Message: "Error placing Get Http request",
+		Kind:    KindNetwork,
+	})
+}
+
+func TestPing_Reachable(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError} {
+		pageSize := ""
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pageSize = r.URL.Query().Get("page[size]")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error_message":"nope"}`))
+		}))
+
+		clientFactory := AccountsHttpClientFactory{}
+		client, _ := clientFactory.MakeClient(server.URL)
+		httpErr := client.Ping(context.Background())
+		server.Close()
+
+		assertHttpError(t, httpErr, nil)
+		if pageSize != "1" {
+			t.Errorf("Expecting a single account to be asked for, got page size=%s", pageSize)
+		}
+	}
+}
+
+func TestPing_Unreachable(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithHttpGetter("http://localhost:8080", func(context.Context, string) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	httpErr := client.Ping(context.Background())
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:   errors.New("connection refused"),
+		Message: "Error placing Get Http request",
+		Kind:    KindNetwork,
+	})
+}
+
+func TestExists(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+		}
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+
+	exists, httpErr := client.Exists(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	assertHttpError(t, httpErr, nil)
+	if !exists {
+		t.Errorf("Expecting the account to exist on 200")
+	}
+
+	status = http.StatusNotFound
+	exists, httpErr = client.Exists(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	assertHttpError(t, httpErr, nil)
+	if exists {
+		t.Errorf("Expecting the account not to exist on 404")
+	}
+
+	status = http.StatusInternalServerError
+	exists, httpErr = client.Exists(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	emptyPayload := make([]byte, 0)
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      500,
+		Status:          "500 Internal Server Error",
+		Message:         "Unexpected response code returned for Head operation, expected 200, got 500",
+		Kind:            KindServer,
+		ResponsePayload: &emptyPayload,
+	})
+	if exists {
+		t.Errorf("Expecting false along with an error")
+	}
+}
+
+func TestExists_IdIsNotUuid(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+	exists, httpErr := client.Exists(context.Background(), "blah")
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "id must be a valid uuid",
+		Kind:    KindValidation,
+	})
+	if exists {
+		t.Errorf("Expecting false along with an error")
+	}
+}
+
+func TestFetchHead_HappyPath(t *testing.T) {
+	method := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		w.Header().Set("ETag", `"v7"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	headers, httpErr := client.FetchHead(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	if method != http.MethodHead {
+		t.Errorf("Expecting a HEAD request, got=%s", method)
+	}
+	if headers.Get("ETag") != `"v7"` {
+		t.Errorf("Expecting the ETag header, got=%s", headers.Get("ETag"))
+	}
+}
+
+func TestFetchHead_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	headers, httpErr := client.FetchHead(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	emptyPayload := make([]byte, 0)
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      404,
+		Status:          "404 Not Found",
+		Message:         "Unexpected response code returned for Head operation, expected 200, got 404",
+		Kind:            KindNotFound,
+		ResponsePayload: &emptyPayload,
+	})
+	if headers != nil {
+		t.Errorf("Expecting headers to be nil")
+	}
+}
+
+func TestFetchHead_NotFoundDeclaringLength(t *testing.T) {
+	// http.NotFound declares the length of the body it would send, which a HEAD response never carries
+	server := httptest.NewServer(http.HandlerFunc(http.NotFound))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	headers, httpErr := client.FetchHead(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	emptyPayload := make([]byte, 0)
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      404,
+		Status:          "404 Not Found",
+		Message:         "Unexpected response code returned for Head operation, expected 200, got 404",
+		Kind:            KindNotFound,
+		ResponsePayload: &emptyPayload,
+	})
+	if headers != nil {
+		t.Errorf("Expecting headers to be nil")
+	}
+
+	exists, httpErr := client.Exists(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	assertHttpError(t, httpErr, nil)
+	if exists {
+		t.Errorf("Expecting the account not to exist")
+	}
+}
+
+func TestFetchHead_IdIsNotUuid(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+	headers, httpErr := client.FetchHead(context.Background(), "blah")
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "id must be a valid uuid",
+		Kind:    KindValidation,
+	})
+	if headers != nil {
+		t.Errorf("Expecting headers to be nil")
+	}
+}
+
+func TestFetchHead_ErrorPlacingRequest(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithHttpHead("https://abc.com", func(context.Context, string) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	headers, httpErr := client.FetchHead(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:   errors.New("connection refused"),
+		Message: "Error placing Head Http request",
+		Kind:    KindNetwork,
+	})
+	if headers != nil {
+		t.Errorf("Expecting headers to be nil")
+	}
+}
+
+func TestListFiltered_EncodesFilters(t *testing.T) {
+	rawQuery := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":[{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}]}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	accounts, _, httpErr := client.ListFiltered(context.Background(), map[string]string{
+		"iban":           "GB82WEST12345698765432",
+		"account_number": "41426819",
+	}, 1, 10)
+
+	assertHttpError(t, httpErr, nil)
+	if len(accounts) != 1 {
+		t.Errorf("Expecting a single account, got=%d", len(accounts))
+	}
+	expected := "filter%5Baccount_number%5D=41426819&filter%5Biban%5D=GB82WEST12345698765432&page%5Bnumber%5D=1&page%5Bsize%5D=10"
+	if rawQuery != expected {
+		t.Errorf("Expecting query=%s, got=%s", expected, rawQuery)
+	}
+}
+
+func TestListFiltered_UnsupportedFilterKey(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+	accounts, links, httpErr := client.ListFiltered(context.Background(), map[string]string{
+		"iban":   "GB82WEST12345698765432",
+		"status": "confirmed",
+	}, 0, 10)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "unsupported filter key: status",
+		Kind:    KindValidation,
+	})
+	if accounts != nil || links != nil {
+		t.Errorf("Expecting accounts and links to be nil")
+	}
+}
+
+func TestDeleteIfExists(t *testing.T) {
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+
+	httpErr := client.DeleteIfExists(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+	assertHttpError(t, httpErr, nil)
+
+	status = http.StatusNotFound
+	httpErr = client.DeleteIfExists(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+	assertHttpError(t, httpErr, nil)
+
+	status = http.StatusConflict
+	httpErr = client.DeleteIfExists(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+	emptyPayload := make([]byte, 0)
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      409,
+		Status:          "409 Conflict",
+		Message:         "Unexpected response code returned for Delete operation, expected 204, got 409",
+		Kind:            KindConflict,
+		ResponsePayload: &emptyPayload,
+	})
+}
+
+func TestFetchVersion(t *testing.T) {
+	tests := map[string]struct {
+		payload         string
+		expectedVersion int64
+		expectedErr     *HTTPError
+	}{
+		"version present": {
+			payload:         `{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","version":7}}`,
+			expectedVersion: 7,
+		},
+		"version zero": {
+			payload:         `{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","version":0}}`,
+			expectedVersion: 0,
+		},
+		"version missing": {
+			payload: `{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`,
+			expectedErr: &HTTPError{
+				Message: "account has no version",
+				Kind:    KindServer,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(test.payload))
+			}))
+			defer server.Close()
+
+			clientFactory := AccountsHttpClientFactory{}
+			client, _ := clientFactory.MakeClient(server.URL)
+			version, httpErr := client.FetchVersion(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+			assertHttpError(t, httpErr, test.expectedErr)
+			if version != test.expectedVersion {
+				t.Errorf("Expecting version=%d, got=%d", test.expectedVersion, version)
+			}
+		})
+	}
+}
+
+func TestFetchVersion_BypassesCache(t *testing.T) {
+	version := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","version":%d}}`, version)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithFetchCache(time.Minute))
+	client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	version = 2
+
+	current, httpErr := client.FetchVersion(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	if current != 2 {
+		t.Errorf("Expecting the current version rather than the cached one, got=%d", current)
+	}
+}
+
+func TestFetchVersion_IdIsNotUuid(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("http://localhost:8080")
+	version, httpErr := client.FetchVersion(context.Background(), "not-a-uuid")
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "id must be a valid uuid",
+		Kind:    KindValidation,
+	})
+	if version != 0 {
+		t.Errorf("Expecting no version, got=%d", version)
+	}
+}
+
+func TestFetchThenDelete(t *testing.T) {
+	var deletes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deletes = append(deletes, r.URL.RawQuery)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","version":3}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	httpErr := client.FetchThenDelete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	if !assertPrimitiveSlices(deletes, []string{"version=3"}) {
+		t.Errorf("Expecting a single delete at the fetched version, got=%v", deletes)
+	}
+}
+
+func TestFetchThenDelete_NoVersion(t *testing.T) {
+	deletes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deletes++
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	httpErr := client.FetchThenDelete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "account has no version",
+		Kind:    KindServer,
+	})
+	if deletes != 0 {
+		t.Errorf("Expecting no delete to be sent, got %d", deletes)
+	}
+}
+
+func TestFetchThenDelete_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	httpErr := client.FetchThenDelete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	if !httpErr.IsNotFound() {
+		t.Errorf("Expecting the fetch's 404 to be returned, got=%v", httpErr)
+	}
+}
+
+// conflictingServer serves a single account whose version a concurrent writer bumps right before
+// each of the first conflicts updates, so that these updates lose the race with a 409.
+func conflictingServer(conflicts int) (*httptest.Server, *int) {
+	version := 1
+	updates := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","version":%d}}`, version)
+			return
+		}
+
+		updates++
+		var requestEnvelope Envelope[AccountData]
+		json.NewDecoder(r.Body).Decode(&requestEnvelope)
+		if updates <= conflicts {
+			version++
+		}
+		if *requestEnvelope.Data.Version != int64(version) {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error_message":"invalid version"}`))
+			return
+		}
+		version++
+		requestEnvelope.Data.Version = Ptr(int64(version))
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(requestEnvelope)
+	}))
+	return server, &updates
+}
+
+func TestUpdateWithRetry_SucceedsAfterConflicts(t *testing.T) {
+	server, updates := conflictingServer(2)
+	defer server.Close()
+
+	var seenVersions []int64
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.UpdateWithRetry(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2",
+		func(current *AccountData) *AccountData {
+			seenVersions = append(seenVersions, *current.Version)
+			return &AccountData{Attributes: &AccountAttributes{Bic: "NWBKGB22"}}
+		}, 3)
+
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{
+		ID:         "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Version:    Ptr(int64(4)),
+		Attributes: &AccountAttributes{Bic: "NWBKGB22"},
+	})
+	if *updates != 3 {
+		t.Errorf("Expecting 3 updates, got=%d", *updates)
+	}
+	// every attempt starts from a fresh fetch, which sees the concurrent writer's version
+	if fmt.Sprint(seenVersions) != "[1 2 3]" {
+		t.Errorf("Expecting mutate to see versions [1 2 3], got=%v", seenVersions)
+	}
+}
+
+func TestUpdateWithRetry_AttemptsExhausted(t *testing.T) {
+	server, updates := conflictingServer(5)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.UpdateWithRetry(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2",
+		func(current *AccountData) *AccountData {
+			return &AccountData{}
+		}, 2)
+
+	payload := []byte(`{"error_message":"invalid version"}`)
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      409,
+		Status:          "409 Conflict",
+		Message:         "Unexpected response code returned for Patch operation, expected 200, got 409 (after 2 attempts)",
+		Kind:            KindConflict,
+		ServerMessage:   "invalid version",
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+	if *updates != 2 {
+		t.Errorf("Expecting 2 updates, got=%d", *updates)
+	}
+}
+
+func TestUpdateWithRetry_FetchFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expecting no update to be sent, got=%s", r.Method)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.UpdateWithRetry(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2",
+		func(current *AccountData) *AccountData {
+			return current
+		}, 3)
+
+	if !httpErr.IsNotFound() {
+		t.Errorf("Expecting the fetch's 404 to be returned, got=%v", httpErr)
+	}
+	assertAccountData(t, account, nil)
+}
+
+func TestUpdateWithRetry_InvalidArguments(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+
+	_, httpErr := client.UpdateWithRetry(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", nil, 3)
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "mutate must not be nil",
+		Kind:    KindValidation,
+	})
+
+	_, httpErr = client.UpdateWithRetry(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2",
+		func(current *AccountData) *AccountData {
+			return current
+		}, 0)
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "max attempts must be positive",
+		Kind:    KindValidation,
+	})
+}
+
+func TestFetchWithETag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v3"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, tag, httpErr := client.FetchWithETag(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+	if tag != `"v3"` {
+		t.Errorf("Expecting etag=\"v3\", got=%s", tag)
+	}
+}
+
+// preconditionServer answers conditional requests with 412 unless their If-Match carries the current tag.
+func preconditionServer(currentTag string, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Match") != currentTag {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusPreconditionFailed)
+			w.Write([]byte(`{"error_message":"etag mismatch"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","version":1}}`))
+		}
+	}))
+}
+
+func TestUpdateIfMatch(t *testing.T) {
+	server := preconditionServer(`"v3"`, http.StatusOK)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+
+	account, httpErr := client.UpdateIfMatch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0, &AccountData{}, `"v3"`)
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2", Version: Ptr(int64(1))})
+
+	account, httpErr = client.UpdateIfMatch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0, &AccountData{}, `"v2"`)
+	payload := []byte(`{"error_message":"etag mismatch"}`)
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      412,
+		Status:          "412 Precondition Failed",
+		Message:         "resource was modified since fetch",
+		Kind:            KindConflict,
+		ServerMessage:   "etag mismatch",
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestDeleteIfMatch(t *testing.T) {
+	server := preconditionServer(`"v3"`, http.StatusNoContent)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+
+	httpErr := client.DeleteIfMatch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0, `"v3"`)
+	assertHttpError(t, httpErr, nil)
+
+	httpErr = client.DeleteIfMatch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0, `"v2"`)
+	if !httpErr.IsConflict() || httpErr.Message != "resource was modified since fetch" {
+		t.Errorf("Expecting a conflict on a stale etag, got=%v", httpErr)
+	}
+}
+
+func TestConditionalRequests_EmptyETag(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("https://abc.com")
+	expected := &HTTPError{
+		Message: "if-match etag must not be empty",
+		Kind:    KindValidation,
+	}
+
+	_, httpErr := client.UpdateIfMatch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0, &AccountData{}, "")
+	assertHttpError(t, httpErr, expected)
+	assertHttpError(t, client.DeleteIfMatch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0, ""), expected)
+}
+
+func TestMakeClient_TrailingSlashInBaseUrl(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "//") {
+			t.Errorf("Expecting no double slash in path, got=%s", r.URL.Path)
+		}
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	for _, baseUrl := range []string{server.URL, server.URL + "/", server.URL + "//"} {
+		client, err := clientFactory.MakeClient(baseUrl)
+		if err != nil {
+			t.Fatalf("Unexpected error for base url %s: %v", baseUrl, err)
+		}
+		client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+		client.HealthCheck(context.Background())
+	}
+
+	expected := []string{
+		"/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2", "/v1/health",
+		"/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2", "/v1/health",
+		"/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2", "/v1/health",
+	}
+	if !assertPrimitiveSlices(paths, expected) {
+		t.Errorf("Expecting paths=%v, got=%v", expected, paths)
+	}
+}
+
+func TestFetch_InjectedDeserializerFails(t *testing.T) {
+	payload := []byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	err := errors.New("cannot deserialize")
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithDeserializer(server.URL,
+		func(data []byte, v any) error {
+			return err
+		})
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:           err,
+		Message:         "Error deserializing json",
+		Kind:            KindSerialization,
+		ResponsePayload: &payload,
+	})
+	if !errors.Is(httpErr.Cause, err) {
+		t.Errorf("Expecting the deserializer's error as the cause, got=%v", httpErr.Cause)
+	}
+	assertAccountData(t, account, nil)
+}
+
+func TestWithDeserializer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	calls := 0
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithDeserializer(func(data []byte, v any) error {
+		calls++
+		return json.Unmarshal(data, v)
+	}))
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+	if calls != 1 {
+		t.Errorf("Expecting the deserializer to be called once, got=%d", calls)
+	}
+
+	if _, err := clientFactory.MakeClient(server.URL, WithDeserializer(nil)); err == nil || err.Error() != "deserializer must not be nil" {
+		t.Errorf("Expecting deserializer validation error, got=%v", err)
+	}
+}
@@ -1,50 +1,30 @@
 package interview_accountapi
 
 import (
-	"strings"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 )
 
 func assertAccountData(t *testing.T, actual *AccountData, expected *AccountData) {
-	if expected != nil && actual == nil {
-		t.Errorf("Expecting account data to be not nil")
-		return
-	}
-
-	if expected == nil && actual != nil {
-		t.Errorf("Expecting acount date to be nil")
-		return
-	}
-
-	if expected == nil && actual == nil {
-		return
-	}
-
-	if expected.ID != actual.ID {
-		t.Errorf("AccountData ID doesn't match, expected=%s, got=%s", expected.ID, actual.ID)
-	}
-
-	if expected.OrganisationID != actual.OrganisationID {
-		t.Errorf("AccountData OrganisationID doesn't match, expected=%s, got=%s", expected.OrganisationID, actual.OrganisationID)
+	t.Helper()
+	for _, diff := range DiffAccountData(expected, actual) {
+		t.Errorf("AccountData doesn't match the expected value (expected != got), %s", diff)
 	}
-
-	if expected.Type != actual.Type {
-		t.Errorf("AccountData Type doesn't match, expected=%s, got=%s", expected.Type, actual.Type)
-	}
-
-	assertPrimitivePointers(t, actual.Version, expected.Version, "Version")
-
-	assertAttributes(t, actual.Attributes, expected.Attributes)
 }
 
-func assertAttributes(t *testing.T, actual *AccountAttributes, expected *AccountAttributes) {
+func assertHttpError(t *testing.T, actual *HTTPError, expected *HTTPError) {
 	if expected != nil && actual == nil {
-		t.Errorf("Expecting account attributes to be not nil")
+		t.Errorf("Expecting http error to be not nil")
 		return
 	}
 
 	if expected == nil && actual != nil {
-		t.Errorf("Expecting account attributes to be nil")
+		t.Errorf("Expecting http error to be nil")
 		return
 	}
 
@@ -52,103 +32,36 @@ func assertAttributes(t *testing.T, actual *AccountAttributes, expected *Account
 		return
 	}
 
-	assertPrimitivePointers(t, actual.AccountClassification, expected.AccountClassification, "Attributes.AccountClassification")
-	assertPrimitivePointers(t, actual.AccountMatchingOptOut, expected.AccountMatchingOptOut, "Attributes.AccountMatchingOptOut")
-	assertPrimitivePointers(t, actual.Country, expected.Country, "Attributes.Country")
-	assertPrimitivePointers(t, actual.JointAccount, expected.JointAccount, "Attributes.JointAccount")
-	assertPrimitivePointers(t, actual.Status, expected.Status, "Attributes.Status")
-	assertPrimitivePointers(t, actual.Switched, expected.Switched, "Attributes.Switched")
-
-	if expected.AccountNumber != actual.AccountNumber {
-		t.Errorf("AccountData Attributes.AccountNumber doesn't match, expected=%s, got=%s",
-			expected.AccountNumber,
-			actual.AccountNumber)
-	}
-
-	if !assertPrimitiveSlices(actual.AlternativeNames, expected.AlternativeNames) {
-		actualAlternativeNamesStr := "nil"
-		expectedAlternativeNamesStr := "nil"
-		if actual.AlternativeNames != nil {
-			actualAlternativeNamesStr = strings.Join(actual.AlternativeNames, ",")
-		}
-		if expected.AlternativeNames != nil {
-			expectedAlternativeNamesStr = strings.Join(expected.AlternativeNames, ",")
-		}
-
-		t.Errorf("AlternativeNames doesn't match with the expected value, expected=%s, got=%s",
-			actualAlternativeNamesStr, expectedAlternativeNamesStr)
-	}
-
-	if expected.BankID != actual.BankID {
-		t.Errorf("AccountData Attributes.BankID doesn't match, expected=%s, got=%s", expected.BankID, actual.BankID)
-	}
-
-	if expected.BankIDCode != actual.BankIDCode {
-		t.Errorf("AccountData Attributes.BankID doesn't match, expected=%s, got=%s", expected.BankIDCode, actual.BankIDCode)
-	}
-
-	if expected.BaseCurrency != actual.BaseCurrency {
-		t.Errorf("AccountData Attributes.BaseCurrency doesn't match, expected=%s, got=%s", expected.BaseCurrency, actual.BaseCurrency)
-	}
-
-	if expected.Bic != actual.Bic {
-		t.Errorf("AccountData Attributes.Bic doesn't match, expected=%s, got=%s", expected.Bic, actual.Bic)
-	}
-
-	if expected.Iban != actual.Iban {
-		t.Errorf("AccountData Attributes.Iban doesn't match, expected=%s, got=%s", expected.Iban, actual.Iban)
-	}
-
-	if expected.CustomerId != actual.CustomerId {
-		t.Errorf("AccountData Attributes.CustomerId doesn't match, expected=%s, got=%s", expected.CustomerId, actual.CustomerId)
-	}
-
-	if expected.SecondaryIdentification != actual.SecondaryIdentification {
-		t.Errorf("AccountData Attributes.SecondaryIdentification doesn't match, expected=%s, got=%s",
-			expected.SecondaryIdentification,
-			actual.SecondaryIdentification)
+	if expected.Cause == nil && actual.Cause != nil {
+		t.Errorf("HttpError cause should be nil")
 	}
 
-	if !assertPrimitiveSlices(actual.Name, expected.Name) {
-		actualNamesStr := "nil"
-		expectedNamesStr := "nil"
-		if actual.Name != nil {
-			actualNamesStr = strings.Join(actual.Name, ",")
-		}
-		if expected.Name != nil {
-			expectedNamesStr = strings.Join(expected.Name, ",")
-		}
-
-		t.Errorf("Name doesn't match with the expected value, expected=%s, got=%s",
-			actualNamesStr, expectedNamesStr)
+	if expected.Cause != nil && actual.Cause == nil {
+		t.Errorf("HttpError cause should not be nil")
 	}
-}
 
-func assertHttpError(t *testing.T, actual *HTTPError, expected *HTTPError) {
-	if expected != nil && actual == nil {
-		t.Errorf("Expecting http error to be not nil")
-		return
+	if actual.Message != expected.Message {
+		t.Errorf("HttpError message doesn't match, expected=%s, got=%s", expected.Message, actual.Message)
 	}
 
-	if expected == nil && actual != nil {
-		t.Errorf("Expecting http error to be nil")
-		return
+	if actual.ServerMessage != expected.ServerMessage {
+		t.Errorf("HttpError server message doesn't match, expected=%s, got=%s", expected.ServerMessage, actual.ServerMessage)
 	}
 
-	if expected == nil && actual == nil {
-		return
+	if actual.Kind != expected.Kind {
+		t.Errorf("HttpError kind doesn't match, expected=%s, got=%s", expected.Kind, actual.Kind)
 	}
 
-	if expected.Cause == nil && actual.Cause != nil {
-		t.Errorf("HttpError cause should be nil")
+	if actual.RetryAfter != expected.RetryAfter {
+		t.Errorf("HttpError retry after doesn't match, expected=%s, got=%s", expected.RetryAfter, actual.RetryAfter)
 	}
 
-	if expected.Cause != nil && actual.Cause == nil {
-		t.Errorf("HttpError cause should not be nil")
+	if actual.Status != expected.Status {
+		t.Errorf("HttpError status doesn't match, expected=%s, got=%s", expected.Status, actual.Status)
 	}
 
-	if actual.Message != expected.Message {
-		t.Errorf("HttpError message doesn't match, expected=%s, got=%s", expected.Message, actual.Message)
+	if actual.RequestID != expected.RequestID {
+		t.Errorf("HttpError request id doesn't match, expected=%s, got=%s", expected.RequestID, actual.RequestID)
 	}
 
 	if actual.StatusCode != expected.StatusCode {
@@ -183,3 +96,84 @@ func assertHttpError(t *testing.T, actual *HTTPError, expected *HTTPError) {
 	}
 
 }
+
+// servedAccount is the account accountServer serves to fetches.
+const servedAccount = `{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","version":0,"attributes":{"name":["Jane Doe"]}}}`
+
+// accountServer stands in for the accounts API, recording every request it gets: creates and updates get their body
+// echoed back, deletes get a 204 and anything else gets servedAccount. Answers are held back by delay, if any.
+type accountServer struct {
+	*httptest.Server
+	delay time.Duration
+
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+type recordedRequest struct {
+	method string
+	body   []byte
+}
+
+func newAccountServer(delay time.Duration) *accountServer {
+	server := &accountServer{delay: delay}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serve))
+	return server
+}
+
+func (s *accountServer) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.requests = append(s.requests, recordedRequest{method: r.Method, body: body})
+	s.mu.Unlock()
+	time.Sleep(s.delay)
+
+	switch r.Method {
+	case http.MethodDelete:
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPost:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	case http.MethodPatch:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(servedAccount))
+	}
+}
+
+// count returns the number of requests received with the given method.
+func (s *accountServer) count(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, req := range s.requests {
+		if req.method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// lastBody returns the body of the last request received with the given method, empty if there is none.
+func (s *accountServer) lastBody(method string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.requests) - 1; i >= 0; i-- {
+		if s.requests[i].method == method {
+			return string(s.requests[i].body)
+		}
+	}
+	return ""
+}
+
+// lastAccount returns the account sent in the last request received with the given method, nil if there is none.
+func (s *accountServer) lastAccount(method string) *AccountData {
+	var envelope Envelope[AccountData]
+	json.Unmarshal([]byte(s.lastBody(method)), &envelope)
+	return envelope.Data
+}
@@ -0,0 +1,103 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const defaultConcurrency = 4
+
+// WithConcurrency caps the number of requests CreateBatch and FetchMany have in flight at once.
+func WithConcurrency(n int) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if n < 1 {
+			return errors.New("concurrency must be positive")
+		}
+		hac.concurrency = n
+		return nil
+	}
+}
+
+func (hac *httpAccountsClientImpl) CreateBatch(ctx context.Context, accounts []*AccountData) ([]*AccountData, []*HTTPError) {
+	created := make([]*AccountData, len(accounts))
+	errs := make([]*HTTPError, len(accounts))
+
+	// a fixed pool of workers, however large the batch, picking the accounts to create by index
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workerCount(hac.concurrency, len(accounts)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				created[i], errs[i] = hac.Create(ctx, accounts[i])
+			}
+		}()
+	}
+	for i := range accounts {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return created, errs
+}
+
+func (hac *httpAccountsClientImpl) FetchMany(ctx context.Context, ids []string) (map[string]*AccountData, map[string]*HTTPError) {
+	fetched := make(map[string]*AccountData)
+	errs := make(map[string]*HTTPError)
+
+	var pending []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if !isValidUUID(id) {
+			errs[id] = &HTTPError{
+				Message: msgInvalidID,
+				Kind:    KindValidation,
+			}
+			continue
+		}
+		pending = append(pending, id)
+	}
+
+	// a fixed pool of workers, as in CreateBatch
+	var mu sync.Mutex
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for w := 0; w < workerCount(hac.concurrency, len(pending)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				account, httpErr := hac.Fetch(ctx, id)
+				mu.Lock()
+				if httpErr != nil {
+					errs[id] = httpErr
+				} else {
+					fetched[id] = account
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, id := range pending {
+		queue <- id
+	}
+	close(queue)
+	wg.Wait()
+
+	return fetched, errs
+}
+
+// workerCount is the number of workers needed for jobs, there is no point in more of them than jobs.
+func workerCount(concurrency, jobs int) int {
+	if jobs < concurrency {
+		return jobs
+	}
+	return concurrency
+}
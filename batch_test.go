@@ -0,0 +1,256 @@
+package interview_accountapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCreateBatch_PartialFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var envelope Envelope[AccountData]
+		json.Unmarshal(body, &envelope)
+		if envelope.Data.ID == "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	ids := []string{
+		"0d209d7f-d07a-4542-947f-5885fddddae2",
+		"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+		"1c8a6a0e-2b0f-4a5e-9f0c-1b6f6a7d2c11",
+	}
+	accounts := make([]*AccountData, len(ids))
+	for i, id := range ids {
+		accounts[i] = NewAccountDataBuilder().WithID(id).Build()
+	}
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithConcurrency(2))
+	created, errs := client.CreateBatch(context.Background(), accounts)
+
+	if len(created) != len(accounts) || len(errs) != len(accounts) {
+		t.Fatalf("Expecting results aligned with the input, got %d accounts and %d errors", len(created), len(errs))
+	}
+	emptyPayload := make([]byte, 0)
+	assertAccountData(t, created[0], accounts[0])
+	assertHttpError(t, errs[0], nil)
+	assertAccountData(t, created[1], nil)
+	assertHttpError(t, errs[1], &HTTPError{
+		StatusCode:      409,
+		Status:          "409 Conflict",
+		Message:         "Unexpected response code returned for Post operation, expected 201, got 409",
+		Kind:            KindConflict,
+		ResponsePayload: &emptyPayload,
+	})
+	assertAccountData(t, created[2], accounts[2])
+	assertHttpError(t, errs[2], nil)
+}
+
+func TestCreateBatch_CapsConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	accounts := make([]*AccountData, 10)
+	for i := range accounts {
+		accounts[i] = NewAccountDataBuilder().Build()
+	}
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithConcurrency(3))
+	_, errs := client.CreateBatch(context.Background(), accounts)
+
+	for i, httpErr := range errs {
+		if httpErr != nil {
+			t.Errorf("Expecting account %d to be created, got=%v", i, httpErr)
+		}
+	}
+	if maxInFlight > 3 {
+		t.Errorf("Expecting at most 3 creates in flight, got=%d", maxInFlight)
+	}
+}
+
+func TestCreateBatch_FixedWorkerPool(t *testing.T) {
+	release := make(chan struct{})
+	var inFlight sync.WaitGroup
+	inFlight.Add(3)
+	created := `{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithRequestInvoker("http://localhost:8080",
+		func(req *http.Request) (*http.Response, error) {
+			inFlight.Done()
+			<-release
+			return &http.Response{
+				StatusCode:    http.StatusCreated,
+				Header:        http.Header{"Content-Type": []string{"application/json"}},
+				Body:          io.NopCloser(strings.NewReader(created)),
+				ContentLength: -1,
+			}, nil
+		}, WithConcurrency(3))
+
+	accounts := make([]*AccountData, 1000)
+	for i := range accounts {
+		accounts[i] = NewAccountDataBuilder().Build()
+	}
+	before := runtime.NumGoroutine()
+	done := make(chan []*HTTPError)
+	go func() {
+		_, errs := client.CreateBatch(context.Background(), accounts)
+		done <- errs
+	}()
+
+	// the first 3 creates are in flight, the other accounts must be waiting without a goroutine of their own
+	inFlight.Wait()
+	if during := runtime.NumGoroutine(); during > before+10 {
+		t.Errorf("Expecting a fixed pool of workers, got %d goroutines on top of %d", during-before, before)
+	}
+	inFlight.Add(len(accounts) - 3)
+	close(release)
+
+	for i, httpErr := range <-done {
+		if httpErr != nil {
+			t.Errorf("Expecting account %d to be created, got=%v", i, httpErr)
+		}
+	}
+}
+
+func TestCreateBatch_Empty(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("http://localhost:8080")
+	created, errs := client.CreateBatch(context.Background(), nil)
+
+	if len(created) != 0 || len(errs) != 0 {
+		t.Errorf("Expecting empty results, got %d accounts and %d errors", len(created), len(errs))
+	}
+}
+
+func TestWithConcurrency_Invalid(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithConcurrency(0))
+	if err == nil || err.Error() != "concurrency must be positive" {
+		t.Errorf("Expecting concurrency validation error, got=%v", err)
+	}
+}
+
+func TestFetchMany(t *testing.T) {
+	var mu sync.Mutex
+	requested := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/v1/organisation/accounts/"):]
+		mu.Lock()
+		requested[id]++
+		mu.Unlock()
+		if id == "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"` + id + `"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithConcurrency(2))
+	fetched, errs := client.FetchMany(context.Background(), []string{
+		"0d209d7f-d07a-4542-947f-5885fddddae2",
+		"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+		"blah",
+		"1c8a6a0e-2b0f-4a5e-9f0c-1b6f6a7d2c11",
+		"0d209d7f-d07a-4542-947f-5885fddddae2",
+	})
+
+	if len(fetched) != 2 || len(errs) != 2 {
+		t.Fatalf("Expecting 2 accounts and 2 errors, got %d accounts and %d errors", len(fetched), len(errs))
+	}
+	assertAccountData(t, fetched["0d209d7f-d07a-4542-947f-5885fddddae2"], &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+	assertAccountData(t, fetched["1c8a6a0e-2b0f-4a5e-9f0c-1b6f6a7d2c11"], &AccountData{ID: "1c8a6a0e-2b0f-4a5e-9f0c-1b6f6a7d2c11"})
+	emptyPayload := make([]byte, 0)
+	assertHttpError(t, errs["ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"], &HTTPError{
+		StatusCode:      404,
+		Status:          "404 Not Found",
+		Message:         "Unexpected response code returned for Get operation, expected 200, got 404",
+		Kind:            KindNotFound,
+		ResponsePayload: &emptyPayload,
+	})
+	assertHttpError(t, errs["blah"], &HTTPError{
+		Message: "id must be a valid uuid",
+		Kind:    KindValidation,
+	})
+	if len(requested) != 3 || requested["0d209d7f-d07a-4542-947f-5885fddddae2"] != 1 {
+		t.Errorf("Expecting every valid id to be fetched once, got=%v", requested)
+	}
+}
+
+func TestFetchMany_FixedWorkerPool(t *testing.T) {
+	release := make(chan struct{})
+	var inFlight sync.WaitGroup
+	inFlight.Add(3)
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithRequestInvoker("http://localhost:8080",
+		func(req *http.Request) (*http.Response, error) {
+			inFlight.Done()
+			<-release
+			id := req.URL.Path[len("/v1/organisation/accounts/"):]
+			return &http.Response{
+				StatusCode:    http.StatusOK,
+				Header:        http.Header{"Content-Type": []string{"application/json"}},
+				Body:          io.NopCloser(strings.NewReader(`{"data":{"id":"` + id + `"}}`)),
+				ContentLength: -1,
+			}, nil
+		}, WithConcurrency(3))
+
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = NewAccountDataBuilder().Build().ID
+	}
+	before := runtime.NumGoroutine()
+	done := make(chan map[string]*HTTPError)
+	go func() {
+		_, errs := client.FetchMany(context.Background(), ids)
+		done <- errs
+	}()
+
+	// the first 3 fetches are in flight, the other ids must be waiting without a goroutine of their own
+	inFlight.Wait()
+	if during := runtime.NumGoroutine(); during > before+10 {
+		t.Errorf("Expecting a fixed pool of workers, got %d goroutines on top of %d", during-before, before)
+	}
+	inFlight.Add(len(ids) - 3)
+	close(release)
+
+	if errs := <-done; len(errs) != 0 {
+		t.Errorf("Expecting every account to be fetched, got=%v", errs)
+	}
+}
@@ -0,0 +1,172 @@
+package interview_accountapi
+
+import (
+	"github.com/google/uuid"
+)
+
+// AccountDataBuilder assembles an AccountData without the caller having to take the address
+// of a local variable for every optional attribute.
+// The zero value is an empty builder ready to use, as is the one returned by NewAccountDataBuilder.
+type AccountDataBuilder struct {
+	account    AccountData
+	attributes AccountAttributes
+}
+
+// NewAccountDataBuilder starts an empty account, set only what the request needs.
+func NewAccountDataBuilder() *AccountDataBuilder {
+	return &AccountDataBuilder{}
+}
+
+func (b *AccountDataBuilder) WithID(id string) *AccountDataBuilder {
+	b.account.ID = id
+	return b
+}
+
+func (b *AccountDataBuilder) WithOrganisationID(organisationID string) *AccountDataBuilder {
+	b.account.OrganisationID = organisationID
+	return b
+}
+
+func (b *AccountDataBuilder) WithType(t string) *AccountDataBuilder {
+	b.account.Type = t
+	return b
+}
+
+func (b *AccountDataBuilder) WithVersion(version int64) *AccountDataBuilder {
+	b.account.Version = &version
+	return b
+}
+
+func (b *AccountDataBuilder) WithAcceptanceQualifier(qualifier string) *AccountDataBuilder {
+	b.attributes.AcceptanceQualifier = &qualifier
+	return b
+}
+
+func (b *AccountDataBuilder) WithAccountClassification(classification string) *AccountDataBuilder {
+	b.attributes.AccountClassification = &classification
+	return b
+}
+
+// WithClassification is the typed counterpart of WithAccountClassification.
+func (b *AccountDataBuilder) WithClassification(classification AccountClassification) *AccountDataBuilder {
+	b.attributes.AccountClassification = classification.Ptr()
+	return b
+}
+
+func (b *AccountDataBuilder) WithAccountMatchingOptOut(optOut bool) *AccountDataBuilder {
+	b.attributes.AccountMatchingOptOut = &optOut
+	return b
+}
+
+func (b *AccountDataBuilder) WithAccountNumber(accountNumber string) *AccountDataBuilder {
+	b.attributes.AccountNumber = accountNumber
+	return b
+}
+
+func (b *AccountDataBuilder) WithAlternativeNames(names ...string) *AccountDataBuilder {
+	b.attributes.AlternativeNames = names
+	return b
+}
+
+func (b *AccountDataBuilder) WithBankID(bankID string) *AccountDataBuilder {
+	b.attributes.BankID = bankID
+	return b
+}
+
+func (b *AccountDataBuilder) WithBankIDCode(bankIDCode string) *AccountDataBuilder {
+	b.attributes.BankIDCode = bankIDCode
+	return b
+}
+
+func (b *AccountDataBuilder) WithBaseCurrency(currency string) *AccountDataBuilder {
+	b.attributes.BaseCurrency = currency
+	return b
+}
+
+func (b *AccountDataBuilder) WithBic(bic string) *AccountDataBuilder {
+	b.attributes.Bic = bic
+	return b
+}
+
+func (b *AccountDataBuilder) WithCountry(country string) *AccountDataBuilder {
+	b.attributes.Country = &country
+	return b
+}
+
+func (b *AccountDataBuilder) WithCustomerId(customerId string) *AccountDataBuilder {
+	b.attributes.CustomerId = customerId
+	return b
+}
+
+func (b *AccountDataBuilder) WithIban(iban string) *AccountDataBuilder {
+	b.attributes.Iban = iban
+	return b
+}
+
+func (b *AccountDataBuilder) WithJointAccount(jointAccount bool) *AccountDataBuilder {
+	b.attributes.JointAccount = &jointAccount
+	return b
+}
+
+func (b *AccountDataBuilder) WithName(name ...string) *AccountDataBuilder {
+	b.attributes.Name = name
+	return b
+}
+
+func (b *AccountDataBuilder) WithNameMatchingStatus(status string) *AccountDataBuilder {
+	b.attributes.NameMatchingStatus = &status
+	return b
+}
+
+func (b *AccountDataBuilder) WithProcessingPurpose(purpose string) *AccountDataBuilder {
+	b.attributes.ProcessingPurpose = &purpose
+	return b
+}
+
+func (b *AccountDataBuilder) WithReferenceMask(mask string) *AccountDataBuilder {
+	b.attributes.ReferenceMask = &mask
+	return b
+}
+
+func (b *AccountDataBuilder) WithSecondaryIdentification(secondaryIdentification string) *AccountDataBuilder {
+	b.attributes.SecondaryIdentification = secondaryIdentification
+	return b
+}
+
+func (b *AccountDataBuilder) WithStatus(status string) *AccountDataBuilder {
+	b.attributes.Status = &status
+	return b
+}
+
+// WithAccountStatus is the typed counterpart of WithStatus.
+func (b *AccountDataBuilder) WithAccountStatus(status AccountStatus) *AccountDataBuilder {
+	b.attributes.Status = status.Ptr()
+	return b
+}
+
+func (b *AccountDataBuilder) WithSwitched(switched bool) *AccountDataBuilder {
+	b.attributes.Switched = &switched
+	return b
+}
+
+func (b *AccountDataBuilder) WithValidationType(validationType string) *AccountDataBuilder {
+	b.attributes.ValidationType = &validationType
+	return b
+}
+
+// Build returns the assembled AccountData, a random UUID is used as the ID unless one was set
+// and Type defaults to "accounts".
+// The result shares nothing with the builder, so the builder may be reused, e.g. to stamp out
+// several accounts differing in a single attribute, each of them with its own generated ID.
+func (b *AccountDataBuilder) Build() *AccountData {
+	account := b.account
+	account.Attributes = &b.attributes
+	account = *account.Clone()
+	if account.ID == "" {
+		account.ID = uuid.NewString()
+	}
+	if account.Type == "" {
+		account.Type = "accounts"
+	}
+	return &account
+}
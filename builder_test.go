@@ -0,0 +1,148 @@
+package interview_accountapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAccountDataBuilder_SameWireFormatAsHandBuilt(t *testing.T) {
+	accountMatchingOptOut := false
+	country := "CA"
+	jointAccount := true
+	status := "pending"
+	switched := true
+	version := int64(0)
+	handBuilt := &AccountData{
+		Attributes: &AccountAttributes{
+			AccountMatchingOptOut:   &accountMatchingOptOut,
+			AccountNumber:           "A1234567",
+			AlternativeNames:        []string{"x", "y", "z"},
+			BankID:                  "GBDSC",
+			BankIDCode:              "BIDC",
+			BaseCurrency:            "CAD",
+			Bic:                     "AAAAAABB",
+			Country:                 &country,
+			Iban:                    "II00",
+			JointAccount:            &jointAccount,
+			Name:                    []string{"a", "b", "c"},
+			SecondaryIdentification: "Driver's License",
+			Status:                  &status,
+			Switched:                &switched,
+		},
+		ID:             "0d209d7f-d07a-4542-947f-5885fddddae2",
+		OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43",
+		Type:           "accounts",
+		Version:        &version,
+	}
+
+	built := NewAccountDataBuilder().
+		WithID("0d209d7f-d07a-4542-947f-5885fddddae2").
+		WithOrganisationID("ba61483c-d5c5-4f50-ae81-6b8c039bea43").
+		WithVersion(0).
+		WithAccountMatchingOptOut(false).
+		WithAccountNumber("A1234567").
+		WithAlternativeNames("x", "y", "z").
+		WithBankID("GBDSC").
+		WithBankIDCode("BIDC").
+		WithBaseCurrency("CAD").
+		WithBic("AAAAAABB").
+		WithCountry("CA").
+		WithIban("II00").
+		WithJointAccount(true).
+		WithName("a", "b", "c").
+		WithSecondaryIdentification("Driver's License").
+		WithStatus("pending").
+		WithSwitched(true).
+		Build()
+
+	expected, _ := json.Marshal(handBuilt)
+	actual, _ := json.Marshal(built)
+	if string(expected) != string(actual) {
+		t.Errorf("Wire format mismatch, expected=%s, got=%s", expected, actual)
+	}
+}
+
+func TestAccountDataBuilder_Defaults(t *testing.T) {
+	account := NewAccountDataBuilder().Build()
+
+	if !isValidUUID(account.ID) {
+		t.Errorf("Expecting a generated uuid, got=%s", account.ID)
+	}
+	if account.Type != "accounts" {
+		t.Errorf("Expecting type to default to accounts, got=%s", account.Type)
+	}
+	if account.Version != nil {
+		t.Errorf("Expecting no version, got=%d", *account.Version)
+	}
+}
+
+func TestAccountDataBuilder_BuildsIndependentAccounts(t *testing.T) {
+	builder := NewAccountDataBuilder().WithCountry("GB").WithName("a")
+	first := builder.Build()
+	second := builder.WithCountry("CA").Build()
+
+	first.Attributes.Name[0] = "changed"
+
+	if *first.Attributes.Country != "GB" || *second.Attributes.Country != "CA" {
+		t.Errorf("Expecting countries GB and CA, got=%s and %s", *first.Attributes.Country, *second.Attributes.Country)
+	}
+	if second.Attributes.Name[0] != "a" {
+		t.Errorf("Expecting the second account to be unaffected, got=%s", second.Attributes.Name[0])
+	}
+	if first.ID == second.ID {
+		t.Errorf("Expecting distinct generated ids, got=%s twice", first.ID)
+	}
+}
+
+func TestAccountDataBuilder_TypedEnums(t *testing.T) {
+	typed := NewAccountDataBuilder().
+		WithID("0d209d7f-d07a-4542-947f-5885fddddae2").
+		WithClassification(ClassificationBusiness).
+		WithAccountStatus(StatusConfirmed).
+		Build()
+	stringly := NewAccountDataBuilder().
+		WithID("0d209d7f-d07a-4542-947f-5885fddddae2").
+		WithAccountClassification("Business").
+		WithStatus("confirmed").
+		Build()
+
+	typedJson, _ := json.Marshal(typed)
+	stringlyJson, _ := json.Marshal(stringly)
+	if string(typedJson) != string(stringlyJson) {
+		t.Errorf("Expecting the same wire format, got=%s and %s", typedJson, stringlyJson)
+	}
+}
+
+func TestAccountDataBuilder_ExtendedAttributes(t *testing.T) {
+	acceptanceQualifier := "same_day"
+	nameMatchingStatus := "opted_out"
+	processingPurpose := "payments"
+	referenceMask := "############"
+	validationType := "card"
+	handBuilt := &AccountData{
+		Attributes: &AccountAttributes{
+			AcceptanceQualifier: &acceptanceQualifier,
+			NameMatchingStatus:  &nameMatchingStatus,
+			ProcessingPurpose:   &processingPurpose,
+			ReferenceMask:       &referenceMask,
+			ValidationType:      &validationType,
+		},
+		ID:   "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Type: "accounts",
+	}
+
+	built := NewAccountDataBuilder().
+		WithID("0d209d7f-d07a-4542-947f-5885fddddae2").
+		WithAcceptanceQualifier("same_day").
+		WithNameMatchingStatus("opted_out").
+		WithProcessingPurpose("payments").
+		WithReferenceMask("############").
+		WithValidationType("card").
+		Build()
+
+	expected, _ := json.Marshal(handBuilt)
+	actual, _ := json.Marshal(built)
+	if string(expected) != string(actual) {
+		t.Errorf("Wire format mismatch, expected=%s, got=%s", expected, actual)
+	}
+}
@@ -0,0 +1,119 @@
+package interview_accountapi
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithFetchCache makes Fetch serve an account from memory for ttl after it was last fetched, rather than asking
+// the server again. Creating, updating or deleting an account through the client drops it from the cache,
+// changes made by anyone else only show once the entry expires. The fetches behind FetchVersion, UpdateWithRetry
+// and the no-op check of WithSkipNoopUpdates always go to the server, a stale version is of no use to them.
+func WithFetchCache(ttl time.Duration) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if ttl <= 0 {
+			return errors.New("fetch cache ttl must be positive")
+		}
+		hac.cache = &fetchCache{ttl: ttl, entries: map[string]cachedAccount{}}
+		return nil
+	}
+}
+
+// WithGracefulDegradation makes Fetch fall back on the cached account when the server fails, i.e. responds with
+// a 5xx status code, or can't be reached, for up to maxStale after the entry expired, rather than failing outright.
+// FetchWithStaleness tells such stale accounts apart. It only has an effect together with WithFetchCache.
+func WithGracefulDegradation(maxStale time.Duration) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if maxStale <= 0 {
+			return errors.New("max staleness must be positive")
+		}
+		hac.maxStale = maxStale
+		return nil
+	}
+}
+
+// degradable tells whether a failed fetch may fall back on a stale account, see WithGracefulDegradation.
+func degradable(httpErr *HTTPError) bool {
+	return httpErr.IsNetwork() || httpErr.StatusCode >= http.StatusInternalServerError
+}
+
+type cachedAccount struct {
+	account   *AccountData
+	expiresAt time.Time
+}
+
+// fetchCache holds accounts by id, guarded by a mutex as it is shared by all the calls made through the client.
+// Accounts are cloned both on the way in and on the way out, so that callers never get to mutate what is cached.
+// A nil cache caches nothing.
+type fetchCache struct {
+	mu  sync.Mutex
+	ttl time.Duration
+	// maxStale is how long expired entries are kept around for getStale, see WithGracefulDegradation
+	maxStale time.Duration
+	entries  map[string]cachedAccount
+	// sweptAt is when expired entries were last dropped, see put
+	sweptAt time.Time
+}
+
+func (c *fetchCache) get(id string, now time.Time) (*AccountData, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(entry.expiresAt) {
+		if !now.Before(entry.expiresAt.Add(c.maxStale)) {
+			delete(c.entries, id)
+		}
+		return nil, false
+	}
+	return entry.account.Clone(), true
+}
+
+// getStale is get for the expired entries that are still kept around, see WithGracefulDegradation.
+func (c *fetchCache) getStale(id string, now time.Time) (*AccountData, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[id]
+	if !ok || !now.Before(entry.expiresAt.Add(c.maxStale)) {
+		return nil, false
+	}
+	return entry.account.Clone(), true
+}
+
+func (c *fetchCache) put(id string, account *AccountData, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// expired entries of ids that are never fetched again would pile up, they get swept once per ttl at most,
+	// which bounds the cache to the accounts fetched within the last two ttls, plus maxStale
+	if !now.Before(c.sweptAt.Add(c.ttl)) {
+		for cachedID, entry := range c.entries {
+			if !now.Before(entry.expiresAt.Add(c.maxStale)) {
+				delete(c.entries, cachedID)
+			}
+		}
+		c.sweptAt = now
+	}
+	c.entries[id] = cachedAccount{account: account.Clone(), expiresAt: now.Add(c.ttl)}
+}
+
+func (c *fetchCache) invalidate(id string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, id)
+}
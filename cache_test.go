@@ -0,0 +1,279 @@
+package interview_accountapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const cachedAccountID = "0d209d7f-d07a-4542-947f-5885fddddae2"
+
+func TestWithFetchCache_HitReturnsClone(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithFetchCache(time.Minute), WithClock(newFakeClock()))
+
+	first, httpErr := client.Fetch(context.Background(), cachedAccountID)
+	assertHttpError(t, httpErr, nil)
+	first.Attributes.Name[0] = "Mutated"
+
+	second, httpErr := client.Fetch(context.Background(), cachedAccountID)
+	assertHttpError(t, httpErr, nil)
+	if fetches := server.count(http.MethodGet); fetches != 1 {
+		t.Errorf("Expecting the second fetch to be served from the cache, got %d fetches", fetches)
+	}
+	if second.Attributes.Name[0] != "Jane Doe" {
+		t.Errorf("Expecting the cached account to be unaffected by callers, got name=%s", second.Attributes.Name[0])
+	}
+	if second == first {
+		t.Errorf("Expecting every fetch to return its own copy")
+	}
+}
+
+func TestWithFetchCache_Expiry(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	clock := newFakeClock()
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithFetchCache(time.Minute), WithClock(clock))
+
+	client.Fetch(context.Background(), cachedAccountID)
+	clock.Sleep(59 * time.Second)
+	client.Fetch(context.Background(), cachedAccountID)
+	if fetches := server.count(http.MethodGet); fetches != 1 {
+		t.Errorf("Expecting a fetch within the ttl to be served from the cache, got %d fetches", fetches)
+	}
+	clock.Sleep(time.Second)
+	client.Fetch(context.Background(), cachedAccountID)
+	if fetches := server.count(http.MethodGet); fetches != 2 {
+		t.Errorf("Expecting a fetch past the ttl to go to the server, got %d fetches", fetches)
+	}
+}
+
+func TestWithFetchCache_Invalidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(client HttpAccountsClient)
+	}{
+		{"Create", func(client HttpAccountsClient) {
+			client.Create(context.Background(), &AccountData{ID: cachedAccountID})
+		}},
+		{"Update", func(client HttpAccountsClient) {
+			client.Update(context.Background(), cachedAccountID, 0, &AccountData{})
+		}},
+		{"Delete", func(client HttpAccountsClient) {
+			client.Delete(context.Background(), cachedAccountID, 0)
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newAccountServer(0)
+			defer server.Close()
+
+			clientFactory := AccountsHttpClientFactory{}
+			client, _ := clientFactory.MakeClient(server.URL, WithFetchCache(time.Minute), WithClock(newFakeClock()))
+
+			client.Fetch(context.Background(), cachedAccountID)
+			test.change(client)
+			client.Fetch(context.Background(), cachedAccountID)
+			if fetches := server.count(http.MethodGet); fetches != 2 {
+				t.Errorf("Expecting %s to invalidate the cached account, got %d fetches", test.name, fetches)
+			}
+		})
+	}
+}
+
+func TestWithFetchCache_NotPositive(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithFetchCache(0))
+	if err == nil || err.Error() != "fetch cache ttl must be positive" {
+		t.Errorf("Expecting the ttl to be rejected, got %v", err)
+	}
+}
+
+func TestWithFetchCache_NoopCheckReadsFromServer(t *testing.T) {
+	version, patches := 2, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPatch {
+			patches++
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error_message":"invalid version"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"data":{"id":"%s","version":%d,"attributes":{"status":"confirmed"}}}`, cachedAccountID, version)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithFetchCache(time.Minute), WithClock(newFakeClock()), WithSkipNoopUpdates())
+	client.Fetch(context.Background(), cachedAccountID)
+	// someone else updates the account, the cached version 2 is now stale
+	version = 3
+
+	status := "confirmed"
+	_, httpErr := client.Update(context.Background(), cachedAccountID, 2, &AccountData{Attributes: &AccountAttributes{Status: &status}})
+
+	if !httpErr.IsConflict() || patches != 1 {
+		t.Errorf("Expecting the update to reach the server and conflict, got %d patches and error=%v", patches, httpErr)
+	}
+}
+
+func TestWithFetchCache_UpdateWithRetryReadsFromServer(t *testing.T) {
+	version := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPatch {
+			var envelope Envelope[AccountData]
+			json.NewDecoder(r.Body).Decode(&envelope)
+			if *envelope.Data.Version != int64(version) {
+				w.WriteHeader(http.StatusConflict)
+				w.Write([]byte(`{"error_message":"invalid version"}`))
+				return
+			}
+			version++
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"data":{"id":"%s","version":%d}}`, cachedAccountID, version)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithFetchCache(time.Minute), WithClock(newFakeClock()))
+	client.Fetch(context.Background(), cachedAccountID)
+	// someone else updates the account, the cached version 1 is now stale
+	version = 2
+
+	updated, httpErr := client.UpdateWithRetry(context.Background(), cachedAccountID, func(a *AccountData) *AccountData {
+		return a
+	}, 1)
+
+	assertHttpError(t, httpErr, nil)
+	if updated == nil || *updated.Version != 3 {
+		t.Errorf("Expecting the update to apply to the current version at the first attempt, got=%v", updated)
+	}
+}
+
+func TestFetchCache_SweepsExpiredEntries(t *testing.T) {
+	cache := &fetchCache{ttl: time.Minute, entries: map[string]cachedAccount{}}
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		cache.put(fmt.Sprintf("id-%d", i), &AccountData{}, start)
+	}
+	cache.put("fresh", &AccountData{}, start.Add(30*time.Second))
+	if len(cache.entries) != 101 {
+		t.Errorf("Expecting nothing to be swept within the ttl, got %d entries", len(cache.entries))
+	}
+
+	cache.put("later", &AccountData{}, start.Add(time.Minute))
+	if len(cache.entries) != 2 {
+		t.Errorf("Expecting the expired entries to be swept, got %d entries", len(cache.entries))
+	}
+	if _, ok := cache.get("fresh", start.Add(time.Minute)); !ok {
+		t.Errorf("Expecting the unexpired entry to be kept")
+	}
+}
+
+func TestWithGracefulDegradation(t *testing.T) {
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(servedAccount))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL,
+		WithFetchCache(time.Minute), WithGracefulDegradation(time.Hour), WithClock(clock))
+
+	t.Run("fresh hit", func(t *testing.T) {
+		account, stale, httpErr := client.FetchWithStaleness(context.Background(), cachedAccountID)
+		assertHttpError(t, httpErr, nil)
+		if account == nil || stale {
+			t.Errorf("Expecting a fresh account, got=%v stale=%t", account, stale)
+		}
+	})
+
+	t.Run("stale fallback on 5xx", func(t *testing.T) {
+		failing = true
+		clock.Sleep(2 * time.Minute)
+		account, stale, httpErr := client.FetchWithStaleness(context.Background(), cachedAccountID)
+		assertHttpError(t, httpErr, nil)
+		if account == nil || account.ID != cachedAccountID || !stale {
+			t.Errorf("Expecting the stale cached account, got=%v stale=%t", account, stale)
+		}
+	})
+
+	t.Run("failure past max staleness", func(t *testing.T) {
+		clock.Sleep(time.Hour)
+		account, httpErr := client.Fetch(context.Background(), cachedAccountID)
+		if account != nil || httpErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("Expecting the failure to be reported, got=%v and error=%v", account, httpErr)
+		}
+	})
+}
+
+func TestWithGracefulDegradation_NoCachedAccount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL,
+		WithFetchCache(time.Minute), WithGracefulDegradation(time.Hour), WithClock(newFakeClock()))
+	account, stale, httpErr := client.FetchWithStaleness(context.Background(), cachedAccountID)
+
+	if account != nil || stale || httpErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expecting the failure to be reported, got=%v stale=%t and error=%v", account, stale, httpErr)
+	}
+}
+
+func TestWithGracefulDegradation_ClientErrorNotDegraded(t *testing.T) {
+	notFound := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if notFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(servedAccount))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL,
+		WithFetchCache(time.Minute), WithGracefulDegradation(time.Hour), WithClock(clock))
+	client.Fetch(context.Background(), cachedAccountID)
+	notFound = true
+	clock.Sleep(2 * time.Minute)
+
+	account, httpErr := client.Fetch(context.Background(), cachedAccountID)
+	if account != nil || !httpErr.IsNotFound() {
+		t.Errorf("Expecting a 404 not to fall back on the stale account, got=%v and error=%v", account, httpErr)
+	}
+}
+
+func TestWithGracefulDegradation_NotPositive(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithGracefulDegradation(0))
+	if err == nil || err.Error() != "max staleness must be positive" {
+		t.Errorf("Expecting the max staleness to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package interview_accountapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// CanonicalizeJSON returns the canonical form of a json document: object keys are sorted
+// and insignificant whitespace is dropped, so two semantically equal documents produce
+// identical bytes regardless of key ordering or formatting.
+// Numbers are kept verbatim to avoid any float rounding.
+func CanonicalizeJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after json document")
+	}
+
+	return json.Marshal(document)
+}
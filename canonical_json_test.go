@@ -0,0 +1,46 @@
+package interview_accountapi
+
+import (
+	"testing"
+)
+
+func TestCanonicalizeJSON_KeyOrderAndWhitespace(t *testing.T) {
+	a := []byte(`{"data": {"type": "accounts", "id": "123",
+		"attributes": {"name": ["a", "b"], "bic": "NWBKGB22", "version": 0.10}}}`)
+	b := []byte(`{"data":{"attributes":{"bic":"NWBKGB22","version":0.10,"name":["a","b"]},"id":"123","type":"accounts"}}`)
+
+	canonicalA, err := CanonicalizeJSON(a)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	canonicalB, err := CanonicalizeJSON(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if string(canonicalA) != string(canonicalB) {
+		t.Errorf("Canonical forms don't match, a=%s, b=%s", canonicalA, canonicalB)
+	}
+
+	expected := `{"data":{"attributes":{"bic":"NWBKGB22","name":["a","b"],"version":0.10},"id":"123","type":"accounts"}}`
+	if string(canonicalA) != expected {
+		t.Errorf("Unexpected canonical form, expected=%s, got=%s", expected, canonicalA)
+	}
+}
+
+func TestCanonicalizeJSON_DifferentDocuments(t *testing.T) {
+	canonicalA, _ := CanonicalizeJSON([]byte(`{"name":["a","b"]}`))
+	canonicalB, _ := CanonicalizeJSON([]byte(`{"name":["b","a"]}`))
+
+	if string(canonicalA) == string(canonicalB) {
+		t.Errorf("Array ordering is significant and must be preserved")
+	}
+}
+
+func TestCanonicalizeJSON_InvalidDocument(t *testing.T) {
+	for _, payload := range []string{"blah", `{"a":1} {"b":2}`, ""} {
+		if _, err := CanonicalizeJSON([]byte(payload)); err == nil {
+			t.Errorf("Expecting an error for payload %q", payload)
+		}
+	}
+}
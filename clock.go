@@ -0,0 +1,39 @@
+package interview_accountapi
+
+import (
+	"errors"
+	"time"
+)
+
+// Clock is the client's source of time, used for retry backoff, rate limiting, polling and timing requests.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// WithClock makes the client tell time using the given clock rather than the system one,
+// mostly so that tests can advance time instantly instead of waiting out delays.
+func WithClock(clock Clock) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if clock == nil {
+			return errors.New("clock must not be nil")
+		}
+		hac.clock = clock
+		return nil
+	}
+}
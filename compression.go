@@ -0,0 +1,54 @@
+package interview_accountapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const acceptEncoding = "Accept-Encoding"
+const contentEncoding = "Content-Encoding"
+const gzipEncoding = "gzip"
+
+// WithRequestCompression gzips the body of Create and CreateFromReader requests, announcing it with Content-Encoding: gzip.
+// Only worth it for servers known to accept compressed requests, the accounts API does not by default.
+func WithRequestCompression() ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		hac.compressRequests = true
+		return nil
+	}
+}
+
+// isGzipped tells whether the server compressed the response body.
+func isGzipped(resp *http.Response) bool {
+	return strings.EqualFold(resp.Header.Get(contentEncoding), gzipEncoding)
+}
+
+// decompress inflates a gzipped response body, any other body is returned as is.
+func (hac *httpAccountsClientImpl) decompress(resp *http.Response, responseData []byte) ([]byte, error) {
+	if !isGzipped(resp) {
+		return responseData, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(responseData))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	// the limit applies to the inflated body too, a small gzipped payload can expand into a huge one
+	return hac.readLimited(reader)
+}
+
+// gzipped compresses body, buffering it so that the request still knows its length.
+func gzipped(body io.Reader) (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := io.Copy(writer, body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
@@ -0,0 +1,97 @@
+package interview_accountapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func gzipBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	writer.Write(data)
+	writer.Close()
+	return buf.Bytes()
+}
+
+func TestFetch_GzippedResponse(t *testing.T) {
+	acceptEncoding := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusOK)
+		w.Write(gzipBytes([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","type":"accounts"}}`)))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{
+		ID:   "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Type: "accounts",
+	})
+	if acceptEncoding != "gzip" {
+		t.Errorf("Expecting Accept-Encoding gzip, got=%s", acceptEncoding)
+	}
+}
+
+func TestFetch_CorruptGzippedResponse(t *testing.T) {
+	payload := []byte("definitely not gzip")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusOK)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	_, err := gzip.NewReader(bytes.NewReader(payload))
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:           err,
+		Message:         "Error decompressing response body",
+		Kind:            KindSerialization,
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestWithRequestCompression_Create(t *testing.T) {
+	var contentEncoding string
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentEncoding = r.Header.Get("Content-Encoding")
+		reader, err := gzip.NewReader(r.Body)
+		if err == nil {
+			received, _ = io.ReadAll(reader)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRequestCompression())
+	_, httpErr := client.Create(context.Background(), &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+
+	assertHttpError(t, httpErr, nil)
+	if contentEncoding != "gzip" {
+		t.Errorf("Expecting Content-Encoding gzip, got=%s", contentEncoding)
+	}
+	expected := `{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`
+	if string(received) != expected {
+		t.Errorf("Expecting the decompressed body=%s, got=%s", expected, received)
+	}
+}
@@ -0,0 +1,80 @@
+package interview_accountapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestClient_ConcurrentUse is meant to be run with the race detector, i.e. go test -race,
+// which reports any unguarded state shared between calls.
+func TestClient_ConcurrentUse(t *testing.T) {
+	var fetches, creates, deletes int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			atomic.AddInt64(&fetches, 1)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","version":0}}`))
+		case http.MethodPost:
+			atomic.AddInt64(&creates, 1)
+			var envelope Envelope[AccountData]
+			json.NewDecoder(r.Body).Decode(&envelope)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(envelope)
+		case http.MethodDelete:
+			atomic.AddInt64(&deletes, 1)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL,
+		WithRetry(2, 0),
+		WithAutoRequestID(),
+		WithHeader("X-Tenant", "acme"),
+		WithMetrics(noopMetrics{}),
+		WithRateLimit(10000, 100))
+
+	const goroutines = 50
+	var wg sync.WaitGroup
+	errs := make(chan string, 3*goroutines)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := context.Background()
+			id := fmt.Sprintf("0d209d7f-d07a-4542-947f-%012d", i)
+
+			if _, httpErr := client.Fetch(ctx, "0d209d7f-d07a-4542-947f-5885fddddae2"); httpErr != nil {
+				errs <- httpErr.Error()
+			}
+			account, httpErr := client.Create(ctx, &AccountData{ID: id})
+			if httpErr != nil {
+				errs <- httpErr.Error()
+			} else if account.ID != id {
+				errs <- fmt.Sprintf("expecting created account %s, got %s", id, account.ID)
+			}
+			if httpErr := client.Delete(ctx, id, 0); httpErr != nil {
+				errs <- httpErr.Error()
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if fetches != goroutines || creates != goroutines || deletes != goroutines {
+		t.Errorf("Expecting %d requests of each kind, got fetches=%d, creates=%d, deletes=%d", goroutines, fetches, creates, deletes)
+	}
+}
@@ -0,0 +1,60 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// timedOperations are the operations WithOperationTimeout applies to, named as their spans are, see WithTracer.
+var timedOperations = map[string]bool{
+	"Fetch":            true,
+	"Create":           true,
+	"CreateFromReader": true,
+	"Update":           true,
+	"Delete":           true,
+	"List":             true,
+	"ListFiltered":     true,
+	"ListAll":          true,
+	"DoRaw":            true,
+	"FetchHead":        true,
+	"Exists":           true,
+	"HealthCheck":      true,
+	"Ping":             true,
+	"Warmup":           true,
+}
+
+// WithOperationTimeout gives the named operation, e.g. "Fetch" or "Create", a deadline of its own, applied whenever
+// it is called with a context having none. Any operation placing requests of its own can be named, see WithTracer,
+// operations built on others, e.g. FetchThenDelete, are bound by the deadlines of those.
+// A deadline set by the caller always takes precedence.
+// Unlike WithTimeout, which bounds every single request, the deadline covers the whole operation,
+// retries and polling included.
+func WithOperationTimeout(operation string, d time.Duration) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if !timedOperations[operation] {
+			return fmt.Errorf("unknown operation: %s", operation)
+		}
+		if d <= 0 {
+			return errors.New("operation timeout must be positive")
+		}
+		if hac.operationTimeouts == nil {
+			hac.operationTimeouts = make(map[string]time.Duration)
+		}
+		hac.operationTimeouts[operation] = d
+		return nil
+	}
+}
+
+// withOperationTimeout applies the deadline configured for operation to ctx, unless ctx has one already.
+func (hac *httpAccountsClientImpl) withOperationTimeout(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	d, ok := hac.operationTimeouts[operation]
+	if !ok {
+		return ctx, func() {}
+	}
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
@@ -0,0 +1,101 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithOperationTimeout_PerOperation(t *testing.T) {
+	server := newAccountServer(100 * time.Millisecond)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL,
+		WithOperationTimeout("Create", 20*time.Millisecond),
+		WithOperationTimeout("Fetch", time.Second))
+
+	_, httpErr := client.Create(context.Background(), &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+	if httpErr == nil || !errors.Is(httpErr.Cause, context.DeadlineExceeded) {
+		t.Errorf("Expecting Create to be cut off at its timeout, got=%v", httpErr)
+	}
+
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	assertHttpError(t, httpErr, nil)
+	if account == nil {
+		t.Errorf("Expecting Fetch to complete within its own timeout")
+	}
+}
+
+func TestWithOperationTimeout_CallerDeadlineWins(t *testing.T) {
+	server := newAccountServer(100 * time.Millisecond)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithOperationTimeout("Fetch", 20*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, httpErr := client.Fetch(ctx, "0d209d7f-d07a-4542-947f-5885fddddae2")
+	assertHttpError(t, httpErr, nil)
+
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer shortCancel()
+	client, _ = clientFactory.MakeClient(server.URL, WithOperationTimeout("Fetch", time.Second))
+	_, httpErr = client.Fetch(shortCtx, "0d209d7f-d07a-4542-947f-5885fddddae2")
+	if httpErr == nil || !errors.Is(httpErr.Cause, context.DeadlineExceeded) {
+		t.Errorf("Expecting Fetch to be cut off at the caller's deadline, got=%v", httpErr)
+	}
+}
+
+func TestWithOperationTimeout_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		timeout   time.Duration
+		expected  string
+	}{
+		{"unknown operation", "Fetchh", time.Second, "unknown operation: Fetchh"},
+		{"zero timeout", "Fetch", 0, "operation timeout must be positive"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clientFactory := AccountsHttpClientFactory{}
+			_, err := clientFactory.MakeClient("http://localhost:8080", WithOperationTimeout(test.operation, test.timeout))
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("Expecting error=%s, got=%v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestWithOperationTimeout_HeadAndHealthOperations(t *testing.T) {
+	server := newAccountServer(100 * time.Millisecond)
+	defer server.Close()
+
+	operations := map[string]func(HttpAccountsClient) *HTTPError{
+		"FetchHead": func(c HttpAccountsClient) *HTTPError {
+			_, httpErr := c.FetchHead(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+			return httpErr
+		},
+		"Exists": func(c HttpAccountsClient) *HTTPError {
+			_, httpErr := c.Exists(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+			return httpErr
+		},
+		"HealthCheck": func(c HttpAccountsClient) *HTTPError { return c.HealthCheck(context.Background()) },
+		"Ping":        func(c HttpAccountsClient) *HTTPError { return c.Ping(context.Background()) },
+		"Warmup":      func(c HttpAccountsClient) *HTTPError { return c.Warmup(context.Background(), 2) },
+	}
+	for name, operation := range operations {
+		t.Run(name, func(t *testing.T) {
+			clientFactory := AccountsHttpClientFactory{}
+			client, _ := clientFactory.MakeClient(server.URL, WithOperationTimeout(name, 20*time.Millisecond))
+
+			httpErr := operation(client)
+			if httpErr == nil || !errors.Is(httpErr.Cause, context.DeadlineExceeded) {
+				t.Errorf("Expecting %s to be cut off at its timeout, got=%v", name, httpErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package interview_accountapi
+
+import (
+	"errors"
+	"net/http"
+)
+
+var errDryRun = errors.New("dry run")
+
+// WithDryRun keeps Create, Update and Delete from reaching the server, they fail with a KindValidation error instead,
+// once the request is fully prepared. Reads go through as usual. The requests they would have sent are handed out
+// by BuildCreateRequest, BuildUpdateRequest and BuildDeleteRequest, which lets the requests of a workflow be inspected
+// without changing anything.
+func WithDryRun() ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		hac.dryRun = true
+		return nil
+	}
+}
+
+// heldBack wraps the request invoker so that only requests which leave the server's state alone are sent.
+func heldBack(doRequest DoRequest) DoRequest {
+	return func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			return nil, errDryRun
+		}
+		return doRequest(req)
+	}
+}
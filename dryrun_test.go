@@ -0,0 +1,174 @@
+package interview_accountapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBuildCreateRequest(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	account := NewAccountDataBuilder().
+		WithID("0d209d7f-d07a-4542-947f-5885fddddae2").
+		WithOrganisationID("ba61483c-d5c5-4f50-ae81-6b8c039bea43").
+		WithCountry("GB").
+		Build()
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithAuthToken("secret"))
+	req, httpErr := client.BuildCreateRequest(context.Background(), account)
+
+	assertHttpError(t, httpErr, nil)
+	if req.Method != http.MethodPost {
+		t.Errorf("Expecting method=POST, got=%s", req.Method)
+	}
+	if !strings.HasSuffix(req.URL.String(), "/v1/organisation/accounts") {
+		t.Errorf("Expecting the accounts resource, got=%s", req.URL)
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expecting Content-Type=application/json, got=%s", req.Header.Get("Content-Type"))
+	}
+	if req.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Expecting the configured headers, got=%v", req.Header)
+	}
+
+	body, _ := io.ReadAll(req.Body)
+	var envelope Envelope[AccountData]
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("Expecting a json body, got=%s", body)
+	}
+	assertAccountData(t, envelope.Data, account)
+	if requests != 0 {
+		t.Errorf("Expecting nothing to be sent, got %d requests", requests)
+	}
+}
+
+func TestBuildCreateRequest_ClientSideValidation(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("http://localhost:8080", WithClientSideValidation())
+	req, httpErr := client.BuildCreateRequest(context.Background(), &AccountData{})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "missing required field: id",
+		Kind:    KindValidation,
+	})
+	if req != nil {
+		t.Errorf("Expecting no request")
+	}
+}
+
+func TestBuildUpdateRequest(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	country := "GB"
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithAuthToken("secret"))
+	req, httpErr := client.BuildUpdateRequest(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 3,
+		&AccountData{Attributes: &AccountAttributes{Country: &country}})
+
+	assertHttpError(t, httpErr, nil)
+	if req.Method != http.MethodPatch || !strings.HasSuffix(req.URL.String(), "/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2") {
+		t.Errorf("Expecting a PATCH of the account, got=%s %s", req.Method, req.URL)
+	}
+	if req.Header.Get("Content-Type") != "application/json" || req.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Expecting the json content type and the configured headers, got=%v", req.Header)
+	}
+
+	body, _ := io.ReadAll(req.Body)
+	var envelope Envelope[AccountData]
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("Expecting a json body, got=%s", body)
+	}
+	version := int64(3)
+	assertAccountData(t, envelope.Data, &AccountData{
+		ID:         "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Version:    &version,
+		Attributes: &AccountAttributes{Country: &country},
+	})
+	if requests := len(server.requests); requests != 0 {
+		t.Errorf("Expecting nothing to be sent, got %d requests", requests)
+	}
+}
+
+func TestBuildDeleteRequest(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithAuthToken("secret"))
+	req, httpErr := client.BuildDeleteRequest(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 3)
+
+	assertHttpError(t, httpErr, nil)
+	if req.Method != http.MethodDelete || !strings.HasSuffix(req.URL.String(), "/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2?version=3") {
+		t.Errorf("Expecting a DELETE of the account at version 3, got=%s %s", req.Method, req.URL)
+	}
+	if req.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Expecting the configured headers, got=%v", req.Header)
+	}
+	if requests := len(server.requests); requests != 0 {
+		t.Errorf("Expecting nothing to be sent, got %d requests", requests)
+	}
+}
+
+func TestBuildUpdateAndDeleteRequest_InvalidArguments(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("http://localhost:8080")
+
+	req, httpErr := client.BuildUpdateRequest(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0, nil)
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "account must not be nil",
+		Kind:    KindValidation,
+	})
+	if req != nil {
+		t.Errorf("Expecting no update request")
+	}
+
+	req, httpErr = client.BuildDeleteRequest(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", -1)
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "version must not be negative",
+		Kind:    KindValidation,
+	})
+	if req != nil {
+		t.Errorf("Expecting no delete request")
+	}
+}
+
+func TestWithDryRun(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithDryRun(), WithRetry(3, 0))
+	expected := &HTTPError{
+		Cause:   errDryRun,
+		Message: "request not sent, the client is in dry run mode",
+		Kind:    KindValidation,
+	}
+
+	_, httpErr := client.Create(context.Background(), &AccountData{})
+	assertHttpError(t, httpErr, expected)
+	_, httpErr = client.Update(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0, &AccountData{})
+	assertHttpError(t, httpErr, expected)
+	assertHttpError(t, client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0), expected)
+
+	_, httpErr = client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	assertHttpError(t, httpErr, nil)
+	if !assertPrimitiveSlices(methods, []string{http.MethodGet}) {
+		t.Errorf("Expecting only the read to be sent, got=%v", methods)
+	}
+}
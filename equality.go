@@ -0,0 +1,126 @@
+package interview_accountapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EqualAccountData tells whether a and b carry the same values, pointer fields are compared by the values they point to.
+// A nil slice is not equal to an empty one, since the two serialize differently.
+func EqualAccountData(a, b *AccountData) bool {
+	return len(DiffAccountData(a, b)) == 0
+}
+
+// DiffAccountData lists the fields a and b differ in, one human-readable entry per field,
+// e.g. `Attributes.Country: "CA" != "GB"`, the value of a coming first. Equal accounts yield no entries.
+func DiffAccountData(a, b *AccountData) []string {
+	if a == nil || b == nil {
+		if a == b {
+			return nil
+		}
+		return []string{fmt.Sprintf("AccountData: %s != %s", nilOrPresent(a), nilOrPresent(b))}
+	}
+
+	var diffs []string
+	diffs = diffValue(diffs, "ID", a.ID, b.ID)
+	diffs = diffValue(diffs, "OrganisationID", a.OrganisationID, b.OrganisationID)
+	diffs = diffValue(diffs, "Type", a.Type, b.Type)
+	diffs = diffPointer(diffs, "Version", a.Version, b.Version)
+
+	if a.Attributes == nil || b.Attributes == nil {
+		if a.Attributes != b.Attributes {
+			diffs = append(diffs, fmt.Sprintf("Attributes: %s != %s", nilOrPresent(a.Attributes), nilOrPresent(b.Attributes)))
+		}
+		return diffs
+	}
+
+	x, y := a.Attributes, b.Attributes
+	diffs = diffPointer(diffs, "Attributes.AcceptanceQualifier", x.AcceptanceQualifier, y.AcceptanceQualifier)
+	diffs = diffPointer(diffs, "Attributes.AccountClassification", x.AccountClassification, y.AccountClassification)
+	diffs = diffPointer(diffs, "Attributes.AccountMatchingOptOut", x.AccountMatchingOptOut, y.AccountMatchingOptOut)
+	diffs = diffValue(diffs, "Attributes.AccountNumber", x.AccountNumber, y.AccountNumber)
+	diffs = diffSlice(diffs, "Attributes.AlternativeNames", x.AlternativeNames, y.AlternativeNames)
+	diffs = diffValue(diffs, "Attributes.BankID", x.BankID, y.BankID)
+	diffs = diffValue(diffs, "Attributes.BankIDCode", x.BankIDCode, y.BankIDCode)
+	diffs = diffValue(diffs, "Attributes.BaseCurrency", x.BaseCurrency, y.BaseCurrency)
+	diffs = diffValue(diffs, "Attributes.Bic", x.Bic, y.Bic)
+	diffs = diffPointer(diffs, "Attributes.Country", x.Country, y.Country)
+	diffs = diffValue(diffs, "Attributes.CustomerId", x.CustomerId, y.CustomerId)
+	diffs = diffValue(diffs, "Attributes.Iban", x.Iban, y.Iban)
+	diffs = diffPointer(diffs, "Attributes.JointAccount", x.JointAccount, y.JointAccount)
+	diffs = diffSlice(diffs, "Attributes.Name", x.Name, y.Name)
+	diffs = diffPointer(diffs, "Attributes.NameMatchingStatus", x.NameMatchingStatus, y.NameMatchingStatus)
+	diffs = diffPointer(diffs, "Attributes.ProcessingPurpose", x.ProcessingPurpose, y.ProcessingPurpose)
+	diffs = diffPointer(diffs, "Attributes.ReferenceMask", x.ReferenceMask, y.ReferenceMask)
+	diffs = diffValue(diffs, "Attributes.SecondaryIdentification", x.SecondaryIdentification, y.SecondaryIdentification)
+	diffs = diffPointer(diffs, "Attributes.Status", x.Status, y.Status)
+	diffs = diffPointer(diffs, "Attributes.Switched", x.Switched, y.Switched)
+	diffs = diffSlice(diffs, "Attributes.UserDefinedData", x.UserDefinedData, y.UserDefinedData)
+	diffs = diffPointer(diffs, "Attributes.ValidationType", x.ValidationType, y.ValidationType)
+	return diffs
+}
+
+// Validate makes sure the account survives being marshaled to json and back without losing anything,
+// reporting the fields that didn't as in DiffAccountData. This catches fields missing a json tag,
+// or carrying a wrong one, as the model grows.
+func (a *AccountData) Validate() error {
+	return validateRoundTrip(a, json.Marshal)
+}
+
+func validateRoundTrip(a *AccountData, marshal func(any) ([]byte, error)) error {
+	data, err := marshal(a)
+	if err != nil {
+		return fmt.Errorf("account can't be marshaled: %w", err)
+	}
+	var roundTripped *AccountData
+	if err := json.Unmarshal(data, &roundTripped); err != nil {
+		return fmt.Errorf("account can't be unmarshaled: %w", err)
+	}
+	if diffs := DiffAccountData(a, roundTripped); len(diffs) > 0 {
+		return fmt.Errorf("account changed in a json round trip: %s", strings.Join(diffs, "; "))
+	}
+	return nil
+}
+
+func diffValue[T comparable](diffs []string, field string, a, b T) []string {
+	if a != b {
+		return append(diffs, fmt.Sprintf("%s: %#v != %#v", field, a, b))
+	}
+	return diffs
+}
+
+func diffPointer[T comparable](diffs []string, field string, a, b *T) []string {
+	if a == nil || b == nil {
+		if a != b {
+			return append(diffs, fmt.Sprintf("%s: %s != %s", field, pointee(a), pointee(b)))
+		}
+		return diffs
+	}
+	return diffValue(diffs, field, *a, *b)
+}
+
+func diffSlice[T comparable](diffs []string, field string, a, b []T) []string {
+	equal := (a == nil) == (b == nil) && len(a) == len(b)
+	for i := 0; equal && i < len(a); i++ {
+		equal = a[i] == b[i]
+	}
+	if !equal {
+		return append(diffs, fmt.Sprintf("%s: %#v != %#v", field, a, b))
+	}
+	return diffs
+}
+
+func pointee[T any](p *T) string {
+	if p == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%#v", *p)
+}
+
+func nilOrPresent[T any](p *T) string {
+	if p == nil {
+		return "nil"
+	}
+	return "present"
+}
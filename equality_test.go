@@ -0,0 +1,111 @@
+package interview_accountapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEqualAccountData_Equal(t *testing.T) {
+	a := NewAccountDataBuilder().WithID("0d209d7f-d07a-4542-947f-5885fddddae2").WithVersion(1).
+		WithCountry("GB").WithName("a", "b").WithJointAccount(false).Build()
+	b := NewAccountDataBuilder().WithID("0d209d7f-d07a-4542-947f-5885fddddae2").WithVersion(1).
+		WithCountry("GB").WithName("a", "b").WithJointAccount(false).Build()
+
+	if !EqualAccountData(a, b) {
+		t.Errorf("Expecting accounts to be equal, got diff=%v", DiffAccountData(a, b))
+	}
+	if !EqualAccountData(nil, nil) {
+		t.Errorf("Expecting nil accounts to be equal")
+	}
+}
+
+func TestDiffAccountData_NilPointers(t *testing.T) {
+	country := "GB"
+	a := &AccountData{ID: "x", Attributes: &AccountAttributes{Country: &country}}
+	b := &AccountData{ID: "x", Attributes: &AccountAttributes{}}
+
+	assertDiff(t, DiffAccountData(a, b), []string{`Attributes.Country: "GB" != nil`})
+	assertDiff(t, DiffAccountData(a, &AccountData{ID: "x"}), []string{`Attributes: present != nil`})
+	assertDiff(t, DiffAccountData(nil, a), []string{`AccountData: nil != present`})
+}
+
+func TestDiffAccountData_DifferingSlices(t *testing.T) {
+	a := &AccountData{Attributes: &AccountAttributes{Name: []string{"a", "b"}, AlternativeNames: []string{}}}
+	b := &AccountData{Attributes: &AccountAttributes{Name: []string{"a", "c"}}}
+
+	assertDiff(t, DiffAccountData(a, b), []string{
+		`Attributes.AlternativeNames: []string{} != []string(nil)`,
+		`Attributes.Name: []string{"a", "b"} != []string{"a", "c"}`,
+	})
+}
+
+func TestDiffAccountData_DifferingValues(t *testing.T) {
+	a := NewAccountDataBuilder().WithID("x").WithVersion(1).WithSwitched(true).WithBic("NWBKGB22").Build()
+	b := NewAccountDataBuilder().WithID("y").WithVersion(2).WithSwitched(false).WithBic("NWBKGB22").Build()
+
+	assertDiff(t, DiffAccountData(a, b), []string{
+		`ID: "x" != "y"`,
+		`Version: 1 != 2`,
+		`Attributes.Switched: true != false`,
+	})
+}
+
+func TestValidate_FullyPopulatedAccount(t *testing.T) {
+	account := NewAccountDataBuilder().
+		WithID("0d209d7f-d07a-4542-947f-5885fddddae2").
+		WithOrganisationID("ba61483c-d5c5-4f50-ae81-6b8c039bea43").
+		WithVersion(1).
+		WithAccountClassification("Personal").
+		WithAccountMatchingOptOut(false).
+		WithAccountNumber("41426819").
+		WithAlternativeNames("Sam Holder").
+		WithBankID("400300").
+		WithBankIDCode("GBDSC").
+		WithBaseCurrency("GBP").
+		WithBic("NWBKGB22").
+		WithCountry("GB").
+		WithCustomerId("123").
+		WithIban("GB11NWBK40030041426819").
+		WithJointAccount(false).
+		WithName("Samantha Holder").
+		WithSecondaryIdentification("A1B2C3D4").
+		WithStatus("confirmed").
+		WithSwitched(false).
+		Build()
+
+	if err := account.Validate(); err != nil {
+		t.Errorf("Expecting the account to survive a round trip, got=%v", err)
+	}
+}
+
+// brokenAccountData mirrors AccountData but for a typo in a json tag, as could slip into the model.
+type brokenAccountData struct {
+	ID             string `json:"id,omitempty"`
+	OrganisationID string `json:"organization_id,omitempty"`
+	Type           string `json:"type,omitempty"`
+}
+
+func TestValidate_BrokenStructTag(t *testing.T) {
+	account := NewAccountDataBuilder().
+		WithID("0d209d7f-d07a-4542-947f-5885fddddae2").
+		WithOrganisationID("ba61483c-d5c5-4f50-ae81-6b8c039bea43").
+		Build()
+	account.Attributes = nil
+
+	err := validateRoundTrip(account, func(v any) ([]byte, error) {
+		a := v.(*AccountData)
+		return json.Marshal(brokenAccountData{ID: a.ID, OrganisationID: a.OrganisationID, Type: a.Type})
+	})
+
+	expected := `account changed in a json round trip: OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43" != ""`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expecting error=%s, got=%v", expected, err)
+	}
+}
+
+func assertDiff(t *testing.T, actual, expected []string) {
+	t.Helper()
+	if !assertPrimitiveSlices(actual, expected) && !(len(actual) == 0 && len(expected) == 0) {
+		t.Errorf("Diff doesn't match, expected=%q, got=%q", expected, actual)
+	}
+}
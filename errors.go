@@ -1,15 +1,269 @@
 package interview_accountapi
 
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrorKind classifies an HTTPError, so that callers don't have to inspect messages or status codes
+// to tell what went wrong.
+type ErrorKind int
+
+const (
+	// KindUnknown is the zero value, it is never assigned by the client.
+	KindUnknown ErrorKind = iota
+	// KindValidation means the request was rejected as invalid, either by the client before sending it
+	// or by the server with a 4xx status code not covered by a more specific kind.
+	KindValidation
+	// KindNotFound means the server responded with status code 404.
+	KindNotFound
+	// KindConflict means the server responded with status code 409, e.g. a version mismatch or a duplicate,
+	// or with 412 to a conditional request, the account having been modified since it was fetched.
+	KindConflict
+	// KindServer means the server failed (5xx) or responded in a way the client can't make sense of.
+	KindServer
+	// KindNetwork means the request couldn't be placed or the response couldn't be read,
+	// including cancellation and timeouts.
+	KindNetwork
+	// KindSerialization means a payload couldn't be serialized or deserialized.
+	KindSerialization
+)
+
+var errorKindNames = map[ErrorKind]string{
+	KindUnknown:       "unknown",
+	KindValidation:    "validation",
+	KindNotFound:      "not_found",
+	KindConflict:      "conflict",
+	KindServer:        "server",
+	KindNetwork:       "network",
+	KindSerialization: "serialization",
+}
+
+func (k ErrorKind) String() string {
+	if name, ok := errorKindNames[k]; ok {
+		return name
+	}
+	return errorKindNames[KindUnknown]
+}
+
+// Sentinel errors matching the HTTPErrors of the corresponding kind with errors.Is,
+// e.g. errors.Is(err, ErrNotFound) holds for an HTTPError of kind KindNotFound, however deeply it is wrapped.
+var (
+	ErrValidation    = errors.New("validation failed")
+	ErrNotFound      = errors.New("not found")
+	ErrConflict      = errors.New("conflict")
+	ErrServer        = errors.New("server failure")
+	ErrNetwork       = errors.New("network failure")
+	ErrSerialization = errors.New("serialization failure")
+)
+
+var kindSentinels = map[ErrorKind]error{
+	KindValidation:    ErrValidation,
+	KindNotFound:      ErrNotFound,
+	KindConflict:      ErrConflict,
+	KindServer:        ErrServer,
+	KindNetwork:       ErrNetwork,
+	KindSerialization: ErrSerialization,
+}
+
+// Messages of the HTTPErrors returned by the client, the ones taking arguments are format strings.
+// Keeping them in one place keeps them consistent across operations. Messages are lower case like any Go error
+// string, but for the capitalized ones callers may already match on, i.e. the ones the client started out with
+// and the ones documented as such, e.g. "Error decompressing response body", which keep their wording.
+const (
+	// messages of requests rejected before being sent, mostly for invalid arguments
+	msgInvalidID              = "id must be a valid uuid"
+	msgEmptyIfMatch           = "if-match etag must not be empty"
+	msgNilAccount             = "account must not be nil"
+	msgNegativeVersion        = "version must not be negative"
+	msgNilReader              = "reader must not be nil"
+	msgNilMutate              = "mutate must not be nil"
+	msgMissingField           = "missing required field: %s"
+	msgEmptyIdempotencyKey    = "idempotency key must not be empty"
+	msgMaxAttemptsNotPositive = "max attempts must be positive"
+	msgUnsupportedFilterKey   = "unsupported filter key: %s"
+	msgPageSizeOutOfRange     = "page size must be between %d and %d"
+	msgNegativePageNumber     = "page number must not be negative"
+	msgWarmupNotPositive      = "number of connections to warm up must be positive"
+	msgInvalidIban            = "iban failed checksum validation"
+	msgInvalidBic             = "bic is not a valid SWIFT code"
+	msgInvalidCountry         = "country must be an ISO 3166-1 alpha-2 code"
+	msgInvalidBankID          = "bank_id for %s must be %d digits"
+	msgInvalidClassification  = "account_classification must be Personal or Business, got %s"
+	msgSchemaViolations       = "payload failed schema validation: %s"
+	msgValidatorFailed        = "account failed validation: %v"
+	msgDryRun                 = "request not sent, the client is in dry run mode"
+	msgSerializingPayload     = "Unable to serialize payload"
+	msgDecodingForSchema      = "unable to decode payload for schema validation"
+	msgValidatingSchema       = "unable to validate payload against schema"
+
+	// messages of failures to talk to the server or to make sense of what it said
+	msgPreparingRequest       = "Error preparing %s Http request"
+	msgPlacingRequest         = "Error placing %s Http request"
+	msgResolvingBaseURL       = "failed to resolve base URL"
+	msgRateLimitWaitCancelled = "rate limiter wait cancelled"
+	msgRequestQueueFull       = "request queue is full, request not sent"
+	msgWarmingUp              = "error warming up connections"
+	msgProcessingBody         = "Error processing response body"
+	msgTruncatedBody          = "truncated response body"
+	msgResponseTooLarge       = "response body exceeded max size"
+	msgDecompressingBody      = "Error decompressing response body"
+	msgUnexpectedStatusCode   = "Unexpected response code returned for %s operation, expected %d, got %d"
+	msgUnexpectedHeader       = "Unexpected %s, expecting %s, got %s"
+	msgModifiedSinceFetch     = "resource was modified since fetch"
+	msgStillExistsAfterDelete = "account still exists after delete"
+	msgDeserializing          = "Error deserializing json"
+	msgEmptyObject            = "Got an empty object after deserialization, json payload was an empty object?"
+	msgEmptyBody              = "empty body on successful response"
+	msgNoVersion              = "account has no version"
+	msgPaginationCycle        = "pagination cycle detected"
+	msgTooManyPages           = "gave up paginating after %d pages"
+	msgParsingNextLink        = "error parsing next page link"
+	msgNoLocation             = "accepted response carries no Location to poll"
+	msgParsingLocation        = "error parsing Location of accepted response"
+	msgGaveUpPolling          = "gave up waiting for accepted operation to complete"
+	msgPollsExhausted         = "accepted operation did not complete after %d polls"
+	msgAfterAttempts          = "%s (after %d attempts)"
+	msgRetryMaxElapsed        = "%s (gave up after %d attempts in %s, the next one would exceed the max elapsed time)"
+)
+
 type HTTPError struct {
-	Cause           error
-	Message         string
-	StatusCode      int
+	Cause   error
+	Message string
+	// ServerMessage carries the error_message returned by the server in json error responses, if any.
+	ServerMessage string
+	Kind          ErrorKind
+	StatusCode    int
+	// Status is the status code of the response along with its text, e.g. "400 Bad Request".
+	Status          string
 	ResponsePayload *[]byte
+	// RetryAfter is how long the server asked to wait before trying again,
+	// set for 429 responses carrying a valid Retry-After header.
+	RetryAfter time.Duration
+	// RequestID is the X-Request-Id header of the response, if the server echoed one, to correlate the failure
+	// with the server's logs.
+	RequestID string
+	// payloadInMessage is the number of bytes of a json ResponsePayload included in Error(), see WithErrorPayloadInMessage.
+	payloadInMessage int
+}
+
+// WithErrorPayloadInMessage makes the errors returned by the client include the response payload in Error(),
+// compacted into a single line and cut at maxBytes, so that logged errors tell what the server said.
+// Only json payloads are included, anything else is left out.
+func WithErrorPayloadInMessage(maxBytes int) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if maxBytes <= 0 {
+			return errors.New("max payload bytes must be positive")
+		}
+		hac.payloadInMessage = maxBytes
+		return nil
+	}
+}
+
+// describePayload equips an error about to be returned to the caller with the payload settings of the client,
+// masking the fields configured with WithFieldScrubber.
+func (hac *httpAccountsClientImpl) describePayload(httpErr *HTTPError) {
+	if httpErr == nil {
+		return
+	}
+	httpErr.payloadInMessage = hac.payloadInMessage
+	if httpErr.ResponsePayload != nil && hac.scrubber != nil {
+		scrubbed := hac.scrubber.scrub(*httpErr.ResponsePayload)
+		httpErr.ResponsePayload = &scrubbed
+	}
 }
 
 func (e *HTTPError) Error() string {
-	if e.Cause == nil {
-		return e.Message
+	if e == nil {
+		return "<nil>"
+	}
+	message := e.Message
+	if e.Status != "" {
+		message = e.Status + ": " + message
+	}
+	if e.ServerMessage != "" {
+		message += " : " + e.ServerMessage
+	}
+	if e.Cause != nil {
+		message += " : " + e.Cause.Error()
+	}
+	if payload := e.compactPayload(); payload != "" {
+		message += " : " + payload
+	}
+	return message
+}
+
+// compactPayload returns the json response payload on a single line, cut at payloadInMessage bytes,
+// or nothing if the payload is not to be included in Error() or is not json.
+// It is computed on every call rather than upfront, most errors never get turned into a string.
+func (e *HTTPError) compactPayload() string {
+	if e.payloadInMessage <= 0 || e.ResponsePayload == nil {
+		return ""
+	}
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, *e.ResponsePayload); err != nil {
+		return ""
+	}
+	if compacted.Len() > e.payloadInMessage {
+		return string(compacted.Bytes()[:e.payloadInMessage]) + "..."
+	}
+	return compacted.String()
+}
+
+// Unwrap returns the cause of the error, so that errors.Is and errors.As look into it,
+// e.g. errors.Is(err, context.Canceled) holds for a request cancelled by its context.
+func (e *HTTPError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Cause
+}
+
+// Is tells whether target is the sentinel error of the kind of e, see ErrNotFound and the like.
+func (e *HTTPError) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+	sentinel, ok := kindSentinels[e.Kind]
+	return ok && target == sentinel
+}
+
+func (e *HTTPError) IsValidation() bool {
+	return e != nil && e.Kind == KindValidation
+}
+
+func (e *HTTPError) IsNotFound() bool {
+	return e != nil && e.Kind == KindNotFound
+}
+
+func (e *HTTPError) IsConflict() bool {
+	return e != nil && e.Kind == KindConflict
+}
+
+func (e *HTTPError) IsServer() bool {
+	return e != nil && e.Kind == KindServer
+}
+
+func (e *HTTPError) IsNetwork() bool {
+	return e != nil && e.Kind == KindNetwork
+}
+
+func (e *HTTPError) IsSerialization() bool {
+	return e != nil && e.Kind == KindSerialization
+}
+
+func kindOfStatusCode(statusCode int) ErrorKind {
+	switch {
+	case statusCode == http.StatusNotFound:
+		return KindNotFound
+	case statusCode == http.StatusConflict, statusCode == http.StatusPreconditionFailed:
+		return KindConflict
+	case statusCode >= 400 && statusCode < 500:
+		return KindValidation
+	default:
+		return KindServer
 	}
-	return e.Message + " : " + e.Cause.Error()
 }
@@ -0,0 +1,228 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestHTTPError_Predicates(t *testing.T) {
+	predicates := map[string]func(*HTTPError) bool{
+		"IsValidation":    (*HTTPError).IsValidation,
+		"IsNotFound":      (*HTTPError).IsNotFound,
+		"IsConflict":      (*HTTPError).IsConflict,
+		"IsServer":        (*HTTPError).IsServer,
+		"IsNetwork":       (*HTTPError).IsNetwork,
+		"IsSerialization": (*HTTPError).IsSerialization,
+	}
+	expectedPredicate := map[ErrorKind]string{
+		KindValidation:    "IsValidation",
+		KindNotFound:      "IsNotFound",
+		KindConflict:      "IsConflict",
+		KindServer:        "IsServer",
+		KindNetwork:       "IsNetwork",
+		KindSerialization: "IsSerialization",
+	}
+
+	for kind, expected := range expectedPredicate {
+		httpErr := &HTTPError{Kind: kind}
+		for name, predicate := range predicates {
+			if predicate(httpErr) != (name == expected) {
+				t.Errorf("%s returned %t for kind %s", name, predicate(httpErr), kind)
+			}
+		}
+	}
+
+	var nilErr *HTTPError
+	for name, predicate := range predicates {
+		if predicate(nilErr) {
+			t.Errorf("%s must be false for a nil error", name)
+		}
+	}
+}
+
+func TestKindOfStatusCode(t *testing.T) {
+	expected := map[int]ErrorKind{
+		400: KindValidation,
+		401: KindValidation,
+		404: KindNotFound,
+		409: KindConflict,
+		500: KindServer,
+		503: KindServer,
+		200: KindServer, // a success status where another one was expected is the server's fault
+	}
+	for statusCode, kind := range expected {
+		if actual := kindOfStatusCode(statusCode); actual != kind {
+			t.Errorf("Kind of status code %d doesn't match, expected=%s, got=%s", statusCode, kind, actual)
+		}
+	}
+}
+
+func TestErrorKind_String(t *testing.T) {
+	if KindNotFound.String() != "not_found" {
+		t.Errorf("Unexpected name for KindNotFound, got=%s", KindNotFound.String())
+	}
+	if ErrorKind(42).String() != "unknown" {
+		t.Errorf("Unexpected name for an undefined kind, got=%s", ErrorKind(42).String())
+	}
+}
+
+func TestHTTPError_PayloadInMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		maxBytes int
+		expected string
+	}{
+		{"off", `{"error_message":"boom"}`, 0, "404 Not Found: Not found"},
+		{"json compacted", "{\n  \"error_message\": \"boom\"\n}", 100, `404 Not Found: Not found : {"error_message":"boom"}`},
+		{"json truncated", `{"error_message":"boom"}`, 10, `404 Not Found: Not found : {"error_me...`},
+		{"not json", "<html>boom</html>", 100, "404 Not Found: Not found"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payload := []byte(test.payload)
+			httpErr := &HTTPError{
+				Message:          "Not found",
+				Status:           "404 Not Found",
+				ResponsePayload:  &payload,
+				payloadInMessage: test.maxBytes,
+			}
+			if httpErr.Error() != test.expected {
+				t.Errorf("Expecting error=%s, got=%s", test.expected, httpErr.Error())
+			}
+		})
+	}
+}
+
+func TestWithErrorPayloadInMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{ "error_message": "record does not exist" }`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithErrorPayloadInMessage(1024))
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	expected := "404 Not Found: Unexpected response code returned for Get operation, expected 200, got 404" +
+		` : record does not exist : {"error_message":"record does not exist"}`
+	if httpErr == nil || httpErr.Error() != expected {
+		t.Errorf("Expecting error=%s, got=%v", expected, httpErr)
+	}
+}
+
+func TestWithErrorPayloadInMessage_NotPositive(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithErrorPayloadInMessage(0))
+
+	if err == nil || err.Error() != "max payload bytes must be positive" {
+		t.Errorf("Expecting max payload bytes validation error, got=%v", err)
+	}
+}
+
+func TestHTTPError_IsSentinel(t *testing.T) {
+	sentinels := map[ErrorKind]error{
+		KindValidation:    ErrValidation,
+		KindNotFound:      ErrNotFound,
+		KindConflict:      ErrConflict,
+		KindServer:        ErrServer,
+		KindNetwork:       ErrNetwork,
+		KindSerialization: ErrSerialization,
+	}
+	for kind, sentinel := range sentinels {
+		httpErr := &HTTPError{Kind: kind}
+		for otherKind, other := range sentinels {
+			if errors.Is(httpErr, other) != (kind == otherKind) {
+				t.Errorf("Expecting errors.Is(%s error, %v)=%t", kind, other, kind == otherKind)
+			}
+		}
+		if !errors.Is(fmt.Errorf("creating account: %w", httpErr), sentinel) {
+			t.Errorf("Expecting a wrapped %s error to match its sentinel", kind)
+		}
+	}
+	if errors.Is(&HTTPError{}, ErrServer) {
+		t.Errorf("Expecting an error of unknown kind to match no sentinel")
+	}
+}
+
+func TestHTTPError_IsOnClientErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+
+	var err error
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	err = httpErr
+	if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) {
+		t.Errorf("Expecting a 404 to be ErrNotFound only, got=%v", err)
+	}
+	err = client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+	if !errors.Is(err, ErrConflict) || errors.Is(err, ErrNotFound) {
+		t.Errorf("Expecting a 409 to be ErrConflict only, got=%v", err)
+	}
+	_, httpErr = client.Fetch(context.Background(), "not-a-uuid")
+	err = httpErr
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("Expecting an invalid id to be ErrValidation, got=%v", err)
+	}
+}
+
+func TestHTTPError_UnwrapsCause(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("http://localhost:8080")
+	_, httpErr := client.Fetch(ctx, "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	var err error = httpErr
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, ErrNetwork) {
+		t.Errorf("Expecting the error to be both context.Canceled and ErrNetwork, got=%v", err)
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Errorf("Expecting the url.Error cause to be reachable with errors.As, got=%v", err)
+	}
+	var asHttpErr *HTTPError
+	if !errors.As(fmt.Errorf("fetching account: %w", err), &asHttpErr) || asHttpErr != httpErr {
+		t.Errorf("Expecting the wrapped HTTPError to be reachable with errors.As, got=%v", asHttpErr)
+	}
+	if (&HTTPError{}).Unwrap() != nil {
+		t.Errorf("Expecting an error without cause to unwrap to nil")
+	}
+}
+
+func TestHTTPError_TypedNil(t *testing.T) {
+	var httpErr *HTTPError
+	var err error = httpErr
+
+	if errors.Is(err, ErrNotFound) || errors.Is(err, context.Canceled) {
+		t.Errorf("Expecting a typed nil to match nothing")
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		t.Errorf("Expecting a typed nil to hold no cause")
+	}
+	var asHttpErr *HTTPError
+	if !errors.As(err, &asHttpErr) || asHttpErr != nil {
+		t.Errorf("Expecting errors.As to yield the typed nil itself, got=%v", asHttpErr)
+	}
+	if err.Error() != "<nil>" {
+		t.Errorf("Expecting a typed nil to read as <nil>, got=%s", err.Error())
+	}
+}
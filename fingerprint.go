@@ -0,0 +1,25 @@
+package interview_accountapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// RequestFingerprint returns a stable hash identifying a request by its method, path and body,
+// meant to spot duplicate or repeated requests (e.g. retry storms) in logs.
+// Json bodies are canonicalized first, so key ordering and whitespace don't affect the fingerprint;
+// any other body is hashed as is.
+func RequestFingerprint(method, path string, body []byte) string {
+	if canonical, err := CanonicalizeJSON(body); err == nil {
+		body = canonical
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(strings.ToUpper(method)))
+	hash.Write([]byte{'\n'})
+	hash.Write([]byte(path))
+	hash.Write([]byte{'\n'})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
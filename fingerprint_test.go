@@ -0,0 +1,44 @@
+package interview_accountapi
+
+import (
+	"testing"
+)
+
+func TestRequestFingerprint_IdenticalRequests(t *testing.T) {
+	path := "/v1/organisation/accounts"
+	a := RequestFingerprint("POST", path, []byte(`{"data":{"id":"123","type":"accounts"}}`))
+	b := RequestFingerprint("post", path, []byte(`{ "data": { "type": "accounts", "id": "123" } }`))
+
+	if a != b {
+		t.Errorf("Semantically identical requests must share a fingerprint, a=%s, b=%s", a, b)
+	}
+	if a != RequestFingerprint("POST", path, []byte(`{"data":{"id":"123","type":"accounts"}}`)) {
+		t.Errorf("Fingerprint must be stable across calls")
+	}
+}
+
+func TestRequestFingerprint_DifferentRequests(t *testing.T) {
+	path := "/v1/organisation/accounts"
+	base := RequestFingerprint("POST", path, []byte(`{"data":{"id":"123"}}`))
+
+	others := map[string]string{
+		"different body":   RequestFingerprint("POST", path, []byte(`{"data":{"id":"456"}}`)),
+		"different method": RequestFingerprint("PATCH", path, []byte(`{"data":{"id":"123"}}`)),
+		"different path":   RequestFingerprint("POST", path+"/123", []byte(`{"data":{"id":"123"}}`)),
+		"no body":          RequestFingerprint("POST", path, nil),
+	}
+	for name, fingerprint := range others {
+		if fingerprint == base {
+			t.Errorf("Expecting a different fingerprint for %s", name)
+		}
+	}
+}
+
+func TestRequestFingerprint_NonJsonBody(t *testing.T) {
+	a := RequestFingerprint("POST", "/path", []byte("blah"))
+	b := RequestFingerprint("POST", "/path", []byte("blah "))
+
+	if a == b {
+		t.Errorf("Non json bodies must be hashed verbatim")
+	}
+}
@@ -0,0 +1,7 @@
+package interview_accountapi
+
+// Ptr returns a pointer to a copy of v, handy for filling in the optional AccountAttributes fields inline,
+// e.g. &AccountAttributes{Country: Ptr("GB")}.
+func Ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,31 @@
+package interview_accountapi
+
+import (
+	"testing"
+)
+
+func TestPtr_DereferencesToInput(t *testing.T) {
+	if s := Ptr("GB"); *s != "GB" {
+		t.Errorf("Expecting GB, got=%s", *s)
+	}
+	if b := Ptr(true); !*b {
+		t.Errorf("Expecting true, got=%v", *b)
+	}
+	if i := Ptr(int64(42)); *i != 42 {
+		t.Errorf("Expecting 42, got=%d", *i)
+	}
+}
+
+func TestPtr_DistinctPointers(t *testing.T) {
+	v := "GB"
+	first := Ptr(v)
+	second := Ptr(v)
+
+	if first == second {
+		t.Errorf("Expecting distinct pointers")
+	}
+	*first = "CA"
+	if *second != "GB" || v != "GB" {
+		t.Errorf("Expecting the other values to be unaffected, got=%s and %s", *second, v)
+	}
+}
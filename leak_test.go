@@ -0,0 +1,218 @@
+package interview_accountapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// closeTrackingTransport counts the response bodies it hands out and how many of them got closed.
+type closeTrackingTransport struct {
+	mu     sync.Mutex
+	opened int
+	closed int
+}
+
+func (c *closeTrackingTransport) wrap(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if resp != nil {
+			c.mu.Lock()
+			c.opened++
+			c.mu.Unlock()
+			resp.Body = &trackedBody{ReadCloser: resp.Body, transport: c}
+		}
+		return resp, err
+	})
+}
+
+func (c *closeTrackingTransport) counts() (int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.opened, c.closed
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	transport *closeTrackingTransport
+	once      sync.Once
+}
+
+func (b *trackedBody) Close() error {
+	b.once.Do(func() {
+		b.transport.mu.Lock()
+		b.transport.closed++
+		b.transport.mu.Unlock()
+	})
+	return b.ReadCloser.Close()
+}
+
+// leakTestServer answers like the accounts API would, or fails in the given way.
+func leakTestServer(mode string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch mode {
+		case "failure":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error_message":"boom"}`))
+			return
+		case "garbage":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`blah`))
+			return
+		case "html":
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`<html>oops</html>`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","version":0}}`))
+		case strings.HasSuffix(r.URL.Path, "/accounts"):
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data":[{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}],"links":{}}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","version":0}}`))
+		}
+	}))
+}
+
+func TestResponseBodiesAlwaysClosed(t *testing.T) {
+	const id = "0d209d7f-d07a-4542-947f-5885fddddae2"
+	operations := map[string]func(HttpAccountsClient){
+		"Fetch":           func(c HttpAccountsClient) { c.Fetch(context.Background(), id) },
+		"FetchWithParams": func(c HttpAccountsClient) { c.FetchWithParams(context.Background(), id, nil) },
+		"FetchHead":       func(c HttpAccountsClient) { c.FetchHead(context.Background(), id) },
+		"Create":          func(c HttpAccountsClient) { c.Create(context.Background(), &AccountData{ID: id}) },
+		"CreateFromReader": func(c HttpAccountsClient) {
+			c.CreateFromReader(context.Background(), strings.NewReader(`{"data":{"id":"`+id+`"}}`))
+		},
+		"Update":      func(c HttpAccountsClient) { c.Update(context.Background(), id, 0, &AccountData{}) },
+		"Delete":      func(c HttpAccountsClient) { c.Delete(context.Background(), id, 0) },
+		"List":        func(c HttpAccountsClient) { c.List(context.Background(), 0, 10) },
+		"ListAll":     func(c HttpAccountsClient) { c.ListAll(context.Background()) },
+		"HealthCheck": func(c HttpAccountsClient) { c.HealthCheck(context.Background()) },
+		"Ping":        func(c HttpAccountsClient) { c.Ping(context.Background()) },
+		"DoRaw":       func(c HttpAccountsClient) { c.DoRaw(context.Background(), http.MethodGet, "/v1/health", nil, nil) },
+	}
+	modes := map[string][]ClientOption{
+		"success":   nil,
+		"failure":   {WithRetry(2, 0)},
+		"garbage":   {WithRetry(2, 0), WithRetryOnDeserializeError()},
+		"html":      nil,
+		"oversized": {WithMaxResponseBytes(16)},
+	}
+	for mode, opts := range modes {
+		for name, operation := range operations {
+			t.Run(mode+"/"+name, func(t *testing.T) {
+				serverMode := mode
+				if mode == "oversized" {
+					serverMode = "success"
+				}
+				server := leakTestServer(serverMode)
+				defer server.Close()
+
+				tracker := &closeTrackingTransport{}
+				clientFactory := AccountsHttpClientFactory{}
+				client, _ := clientFactory.MakeClient(server.URL, append([]ClientOption{WithRoundTripper(tracker.wrap)}, opts...)...)
+				operation(client)
+
+				opened, closed := tracker.counts()
+				if opened == 0 || opened != closed {
+					t.Errorf("Expecting every response body to be closed, %d opened and %d closed", opened, closed)
+				}
+			})
+		}
+	}
+}
+
+func TestResponseBodiesClosedOnCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		// half a payload, the rest never comes before the caller gives up
+		w.Write([]byte(`{"data":[{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	operations := map[string]func(context.Context, HttpAccountsClient) *HTTPError{
+		"Fetch": func(ctx context.Context, c HttpAccountsClient) *HTTPError {
+			_, httpErr := c.Fetch(ctx, "0d209d7f-d07a-4542-947f-5885fddddae2")
+			return httpErr
+		},
+		"List": func(ctx context.Context, c HttpAccountsClient) *HTTPError {
+			_, _, httpErr := c.List(ctx, 0, 10)
+			return httpErr
+		},
+		"DoRaw": func(ctx context.Context, c HttpAccountsClient) *HTTPError {
+			_, _, httpErr := c.DoRaw(ctx, http.MethodGet, "/v1/health", nil, nil)
+			return httpErr
+		},
+	}
+	for name, operation := range operations {
+		t.Run(name, func(t *testing.T) {
+			tracker := &closeTrackingTransport{}
+			clientFactory := AccountsHttpClientFactory{}
+			client, _ := clientFactory.MakeClient(server.URL, WithRoundTripper(tracker.wrap))
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			httpErr := operation(ctx, client)
+
+			if httpErr == nil || !httpErr.IsNetwork() {
+				t.Errorf("Expecting a network error, got=%v", httpErr)
+			}
+			opened, closed := tracker.counts()
+			if opened != 1 || closed != 1 {
+				t.Errorf("Expecting the response body to be closed, %d opened and %d closed", opened, closed)
+			}
+		})
+	}
+}
+
+func TestConnectionReusedAcrossRetries(t *testing.T) {
+	server := leakTestServer("failure")
+	defer server.Close()
+
+	var mu sync.Mutex
+	var reused []bool
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			reused = append(reused, info.Reused)
+		},
+	}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(3, 0))
+	ctx := httptrace.WithClientTrace(context.Background(), trace)
+
+	_, httpErr := client.Fetch(ctx, "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	if httpErr == nil || !httpErr.IsServer() {
+		t.Errorf("Expecting a server error, got=%v", httpErr)
+	}
+	if len(reused) != 3 || reused[0] || !reused[1] || !reused[2] {
+		t.Errorf("Expecting the retries to reuse the first connection, got=%v", reused)
+	}
+}
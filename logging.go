@@ -0,0 +1,82 @@
+package interview_accountapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Logger receives what the client is doing, e.g. to be forwarded to the application's logging library.
+// Implementations must be safe for concurrent use.
+type Logger interface {
+	Debugf(format string, args ...any)
+	Errorf(format string, args ...any)
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Debugf(string, ...any) {}
+func (noopLogger) Errorf(string, ...any) {}
+
+// WithLogger makes the client log the method, url, status code and duration of every request at debug level,
+// along with a RequestFingerprint to tell repeated requests apart and the X-Request-Id header if any,
+// and the cause of failed requests at error level.
+// Response payloads are left out, see WithBodyLogging.
+func WithLogger(logger Logger) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if logger == nil {
+			return errors.New("logger must not be nil")
+		}
+		hac.logger = logger
+		return nil
+	}
+}
+
+// WithBodyLogging additionally logs response payloads at debug level.
+// Payloads carry account data, so this is meant for troubleshooting rather than for production.
+func WithBodyLogging() ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		hac.logBodies = true
+		return nil
+	}
+}
+
+// logRequest logs a request, identified by its fingerprint and request id if any.
+func (hac *httpAccountsClientImpl) logRequest(o *observation) {
+	fingerprint := o.fingerprint
+	if o.requestID != "" {
+		fingerprint += ", request id " + o.requestID
+	}
+	if o.err != nil {
+		hac.logger.Errorf("%s %s failed after %s, fingerprint %s: %v", o.req.Method, o.req.URL, o.elapsed, fingerprint, o.err)
+		return
+	}
+	hac.logger.Debugf("%s %s returned %d in %s, fingerprint %s", o.req.Method, o.req.URL, o.resp.StatusCode, o.elapsed, fingerprint)
+}
+
+// requestBody returns a copy of the request body without consuming it, provided the request knows how to replay it,
+// which is the case for the in-memory bodies the client sends.
+func requestBody(req *http.Request) []byte {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(body)
+	return data
+}
+
+func (hac *httpAccountsClientImpl) logBody(resp *http.Response, responseData []byte) {
+	if !hac.logBodies {
+		return
+	}
+	responseData = hac.scrubber.scrub(responseData)
+	if resp.Request != nil {
+		hac.logger.Debugf("%s %s response body: %s", resp.Request.Method, resp.Request.URL, responseData)
+		return
+	}
+	hac.logger.Debugf("response body: %s", responseData)
+}
@@ -0,0 +1,160 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type capturingLogger struct {
+	mu     sync.Mutex
+	debugs []string
+	errors []string
+}
+
+func (l *capturingLogger) Debugf(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.debugs = append(l.debugs, fmt.Sprintf(format, args...))
+}
+
+func (l *capturingLogger) Errorf(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, fmt.Sprintf(format, args...))
+}
+
+func TestWithLogger_LogsRequestsWithoutBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","attributes":{"iban":"GB82WEST12345698765432"}}}`))
+	}))
+	defer server.Close()
+
+	logger := &capturingLogger{}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithLogger(logger))
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	if len(logger.debugs) != 1 || len(logger.errors) != 0 {
+		t.Fatalf("Expecting a single debug line, got debugs=%v, errors=%v", logger.debugs, logger.errors)
+	}
+	line := logger.debugs[0]
+	url := server.URL + "/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2"
+	expectedPrefix := "GET " + url + " returned 200 in "
+	if !strings.HasPrefix(line, expectedPrefix) {
+		t.Errorf("Expecting the line to start with %q, got=%q", expectedPrefix, line)
+	}
+	if !strings.HasSuffix(line, "fingerprint "+RequestFingerprint("GET", url, nil)) {
+		t.Errorf("Expecting the request fingerprint to be logged, got=%q", line)
+	}
+	if strings.Contains(line, "GB82WEST12345698765432") {
+		t.Errorf("Expecting the payload not to be logged, got=%q", line)
+	}
+}
+
+func TestWithLogger_LogsFailureCause(t *testing.T) {
+	logger := &capturingLogger{}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithRequestInvoker("http://localhost:8080", func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}, WithLogger(logger))
+	client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	if len(logger.errors) != 1 || len(logger.debugs) != 0 {
+		t.Fatalf("Expecting a single error line, got debugs=%v, errors=%v", logger.debugs, logger.errors)
+	}
+	expectedPrefix := "DELETE http://localhost:8080/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2?version=0 failed after "
+	if !strings.HasPrefix(logger.errors[0], expectedPrefix) || !strings.HasSuffix(logger.errors[0], ": connection refused") {
+		t.Errorf("Expecting the method, url and cause to be logged, got=%q", logger.errors[0])
+	}
+}
+
+func TestWithBodyLogging_LogsResponsePayload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	logger := &capturingLogger{}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithLogger(logger), WithBodyLogging())
+	client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	if len(logger.debugs) != 2 {
+		t.Fatalf("Expecting two debug lines, got=%v", logger.debugs)
+	}
+	expected := "GET " + server.URL + "/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2 " +
+		`response body: {"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`
+	if logger.debugs[1] != expected {
+		t.Errorf("Expecting=%q, got=%q", expected, logger.debugs[1])
+	}
+}
+
+func TestWithLogger_Nil(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, err := clientFactory.MakeClient("http://localhost:8080", WithLogger(nil))
+
+	if client != nil || err == nil || err.Error() != "logger must not be nil" {
+		t.Errorf("Expecting the nil logger to be rejected, got client=%v, err=%v", client, err)
+	}
+}
+
+func TestWithLogger_LogsRequestID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	logger := &capturingLogger{}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithLogger(logger), WithHeader("X-Request-Id", "batch-7"))
+	client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	if len(logger.debugs) != 1 || !strings.HasSuffix(logger.debugs[0], ", request id batch-7") {
+		t.Errorf("Expecting the request id to be logged, got=%v", logger.debugs)
+	}
+}
+
+func TestWithoutLogger_RequestBodiesNotFingerprinted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	replays := 0
+	countingReplays := func(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, url, body)
+		if err == nil && req.GetBody != nil {
+			getBody := req.GetBody
+			req.GetBody = func() (io.ReadCloser, error) {
+				replays++
+				return getBody()
+			}
+		}
+		return req, err
+	}
+	clientFactory := AccountsHttpClientFactory{}
+	for _, opts := range [][]ClientOption{nil, {WithLogger(&capturingLogger{})}} {
+		replays = 0
+		client, _ := clientFactory.MakeTestClientWithNewRequestCreator(server.URL, countingReplays, opts...)
+		_, httpErr := client.Create(context.Background(), &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+		assertHttpError(t, httpErr, nil)
+
+		if logging := len(opts) > 0; (replays > 0) != logging {
+			t.Errorf("Expecting the body to be replayed for the fingerprint only when logging=%t, got %d replays", logging, replays)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics receives a sample for every Http request the client sends, e.g. an adapter over Prometheus collectors.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// IncRequest counts a request by its Http method and response status code,
+	// the status code is 0 when no response was received at all.
+	IncRequest(method string, status int)
+	// ObserveLatency records how long it took to get the response, or to fail getting one.
+	ObserveLatency(method string, d time.Duration)
+}
+
+// ContentLengthMetrics is implemented by Metrics that also record the length responses declare for their body,
+// see WithResponseContentLengthMetric.
+type ContentLengthMetrics interface {
+	// ObserveResponseContentLength records the Content-Length a response declared, -1 when it declared none,
+	// e.g. for a chunked response. It is what the server advertised, not the number of bytes read,
+	// e.g. a HEAD response declares the length of a body it doesn't carry.
+	ObserveResponseContentLength(method string, length int64)
+}
+
+// ByteMetrics is implemented by Metrics that also count the bytes of request and response bodies,
+// see WithMetricsForRequestBytes. Sizes are given both as on the wire and uncompressed, the two are the same
+// for bodies that weren't compressed.
+type ByteMetrics interface {
+	// AddBytesSent adds the size of a request body, as sent and before WithRequestCompression gzipped it.
+	AddBytesSent(method string, wire, uncompressed int64)
+	// AddBytesReceived adds the size of a response body read in full, as received and once inflated.
+	AddBytesReceived(method string, wire, uncompressed int64)
+}
+
+// RequestIDMetrics is implemented by Metrics that also want the request id of every request counted,
+// e.g. to attach it as an exemplar, see WithResponseObservabilityContext.
+type RequestIDMetrics interface {
+	// ObserveRequestID records the request id of a request counted by IncRequest with the same method and status code.
+	ObserveRequestID(method string, status int, requestID string)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) IncRequest(string, int)               {}
+func (noopMetrics) ObserveLatency(string, time.Duration) {}
+
+// WithMetrics reports every request sent, retries and polling included, to the given metrics.
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if metrics == nil {
+			return errors.New("metrics must not be nil")
+		}
+		hac.metrics = metrics
+		return nil
+	}
+}
+
+// WithResponseContentLengthMetric reports the Content-Length of every response received, retries and polling included,
+// to the metrics given in WithMetrics, which must implement ContentLengthMetrics.
+// It only has an effect together with WithMetrics.
+func WithResponseContentLengthMetric() ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		hac.reportContentLength = true
+		return nil
+	}
+}
+
+// WithMetricsForRequestBytes reports the size of every request body sent, retries and polling included,
+// and of every response body read, to the metrics given in WithMetrics, which must implement ByteMetrics.
+// It only has an effect together with WithMetrics.
+func WithMetricsForRequestBytes() ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		hac.reportBytes = true
+		return nil
+	}
+}
+
+// uncompressedSizeCtx carries the size of a request body before it got compressed.
+type uncompressedSizeCtx struct{}
+
+func withUncompressedSize(ctx context.Context, size int64) context.Context {
+	return context.WithValue(ctx, uncompressedSizeCtx{}, size)
+}
+
+// meterRequest counts and times a request, along with the optional samples the metrics asked for.
+func (hac *httpAccountsClientImpl) meterRequest(o *observation) {
+	method := o.req.Method
+	hac.metrics.ObserveLatency(method, o.elapsed)
+	hac.metrics.IncRequest(method, o.status())
+	if o.resp != nil && hac.contentLengths != nil {
+		hac.contentLengths.ObserveResponseContentLength(method, o.resp.ContentLength)
+	}
+	if hac.requestIDs != nil && o.requestID != "" {
+		hac.requestIDs.ObserveRequestID(method, o.status(), o.requestID)
+	}
+}
+
+// countingSent returns a copy of req whose body reports the bytes read off it once the transport closes it,
+// which it does after sending the body whatever its length, known or not.
+func (hac *httpAccountsClientImpl) countingSent(req *http.Request) *http.Request {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req
+	}
+	uncompressed, compressed := req.Context().Value(uncompressedSizeCtx{}).(int64)
+	counted := *req
+	counted.Body = &sentBody{ReadCloser: req.Body, sent: func(n int64) {
+		if !compressed {
+			uncompressed = n
+		}
+		hac.byteMetrics.AddBytesSent(req.Method, n, uncompressed)
+	}}
+	return &counted
+}
+
+// countReceived reports the size of a response body read in full.
+func (hac *httpAccountsClientImpl) countReceived(resp *http.Response, wire, uncompressed int64) {
+	if hac.byteMetrics == nil {
+		return
+	}
+	method := ""
+	if resp.Request != nil {
+		method = resp.Request.Method
+	}
+	hac.byteMetrics.AddBytesReceived(method, wire, uncompressed)
+}
+
+// sentBody counts the bytes read off a request body, handing the count to sent when closed for the first time.
+// The transport may close it from another goroutine than the one reading it.
+type sentBody struct {
+	io.ReadCloser
+	n    atomic.Int64
+	once sync.Once
+	sent func(n int64)
+}
+
+func (b *sentBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
+
+func (b *sentBody) Close() error {
+	b.once.Do(func() { b.sent(b.n.Load()) })
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,211 @@
+package interview_accountapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type inMemoryMetrics struct {
+	mu        sync.Mutex
+	requests  map[string]int
+	latencies map[string][]time.Duration
+	lengths   map[string][]int64
+	sent      map[string][2]int64
+	received  map[string][2]int64
+	ids       []string
+}
+
+func newInMemoryMetrics() *inMemoryMetrics {
+	return &inMemoryMetrics{
+		requests:  map[string]int{},
+		latencies: map[string][]time.Duration{},
+		lengths:   map[string][]int64{},
+		sent:      map[string][2]int64{},
+		received:  map[string][2]int64{},
+	}
+}
+
+func (m *inMemoryMetrics) IncRequest(method string, status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[fmt.Sprintf("%s %d", method, status)]++
+}
+
+func (m *inMemoryMetrics) ObserveLatency(method string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latencies[method] = append(m.latencies[method], d)
+}
+
+func (m *inMemoryMetrics) ObserveResponseContentLength(method string, length int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lengths[method] = append(m.lengths[method], length)
+}
+
+func (m *inMemoryMetrics) AddBytesSent(method string, wire, uncompressed int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent[method] = [2]int64{m.sent[method][0] + wire, m.sent[method][1] + uncompressed}
+}
+
+func (m *inMemoryMetrics) AddBytesReceived(method string, wire, uncompressed int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.received[method] = [2]int64{m.received[method][0] + wire, m.received[method][1] + uncompressed}
+}
+
+func (m *inMemoryMetrics) ObserveRequestID(method string, status int, requestID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ids = append(m.ids, fmt.Sprintf("%s %d %s", method, status, requestID))
+}
+
+func TestWithMetrics_FetchSuccessAndFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	metrics := newInMemoryMetrics()
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithMetrics(metrics))
+	client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	client.Fetch(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	client.Fetch(context.Background(), "blah") // never sent
+
+	expected := map[string]int{"GET 200": 2, "GET 404": 1}
+	if len(metrics.requests) != len(expected) {
+		t.Errorf("Expecting requests=%v, got=%v", expected, metrics.requests)
+	}
+	for key, count := range expected {
+		if metrics.requests[key] != count {
+			t.Errorf("Expecting %d %s requests, got=%d", count, key, metrics.requests[key])
+		}
+	}
+	if len(metrics.latencies["GET"]) != 3 {
+		t.Errorf("Expecting 3 latency samples, got=%d", len(metrics.latencies["GET"]))
+	}
+}
+
+func TestWithMetrics_NetworkFailureRecordedWithStatusZero(t *testing.T) {
+	metrics := newInMemoryMetrics()
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithRequestInvoker("http://localhost:8080", func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}, WithMetrics(metrics))
+	client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	if metrics.requests["GET 0"] != 1 || len(metrics.requests) != 1 {
+		t.Errorf("Expecting a single GET 0 request, got=%v", metrics.requests)
+	}
+	if len(metrics.latencies["GET"]) != 1 {
+		t.Errorf("Expecting a single latency sample, got=%d", len(metrics.latencies["GET"]))
+	}
+}
+
+func TestWithResponseContentLengthMetric(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("chunked") == "" {
+			w.Header().Set("Content-Length", "54")
+		}
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	metrics := newInMemoryMetrics()
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithMetrics(metrics), WithResponseContentLengthMetric())
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	assertHttpError(t, httpErr, nil)
+	_, _, httpErr = client.DoRaw(context.Background(), http.MethodGet, "/v1/organisation/accounts?chunked=true", nil, nil)
+	assertHttpError(t, httpErr, nil)
+
+	lengths := metrics.lengths["GET"]
+	if len(lengths) != 2 || lengths[0] != 54 || lengths[1] != -1 {
+		t.Errorf("Expecting the declared length of the fixed-length response and -1 for the chunked one, got=%v", lengths)
+	}
+}
+
+func TestWithResponseContentLengthMetric_UnsupportedMetrics(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithResponseContentLengthMetric(), WithMetrics(noopMetrics{}))
+	if err == nil || err.Error() != "metrics must implement ContentLengthMetrics to report response content lengths" {
+		t.Errorf("Expecting metrics validation error, got=%v", err)
+	}
+
+	// without metrics there is nothing to report to
+	_, err = clientFactory.MakeClient("http://localhost:8080", WithResponseContentLengthMetric())
+	if err != nil {
+		t.Errorf("Expecting no error, got=%v", err)
+	}
+}
+
+func TestWithMetricsForRequestBytes_CreateRoundTrip(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	metrics := newInMemoryMetrics()
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithMetrics(metrics), WithMetricsForRequestBytes())
+	_, httpErr := client.Create(context.Background(), &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+	assertHttpError(t, httpErr, nil)
+
+	// the server echoes the payload, so as many bytes come back as went out
+	size := int64(len(server.lastBody(http.MethodPost)))
+	if expected := [2]int64{size, size}; metrics.sent["POST"] != expected || metrics.received["POST"] != expected {
+		t.Errorf("Expecting %v bytes sent and received, got sent=%v, received=%v", expected, metrics.sent["POST"], metrics.received["POST"])
+	}
+}
+
+func TestWithMetricsForRequestBytes_Compressed(t *testing.T) {
+	var wire, inflated int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compressed, _ := io.ReadAll(r.Body)
+		reader, _ := gzip.NewReader(bytes.NewReader(compressed))
+		payload, _ := io.ReadAll(reader)
+		wire, inflated = int64(len(compressed)), int64(len(payload))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(compressed)
+	}))
+	defer server.Close()
+
+	metrics := newInMemoryMetrics()
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithMetrics(metrics), WithMetricsForRequestBytes(), WithRequestCompression())
+	_, httpErr := client.Create(context.Background(), &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+	assertHttpError(t, httpErr, nil)
+
+	if expected := [2]int64{wire, inflated}; wire == inflated || metrics.sent["POST"] != expected || metrics.received["POST"] != expected {
+		t.Errorf("Expecting %v bytes sent and received, got sent=%v, received=%v", expected, metrics.sent["POST"], metrics.received["POST"])
+	}
+}
+
+func TestWithMetricsForRequestBytes_UnsupportedMetrics(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithMetrics(noopMetrics{}), WithMetricsForRequestBytes())
+	if err == nil || err.Error() != "metrics must implement ByteMetrics to report body sizes" {
+		t.Errorf("Expecting metrics validation error, got=%v", err)
+	}
+}
@@ -4,6 +4,26 @@ type Envelope[T any] struct {
 	Data *T `json:"data,omitempty"`
 }
 
+// ListEnvelope is the counterpart of Envelope for endpoints returning many resources, e.g. a page of accounts.
+type ListEnvelope[T any] struct {
+	Data  []*T           `json:"data"`
+	Links *Links         `json:"links,omitempty"`
+	Meta  map[string]any `json:"meta,omitempty"`
+}
+
+type Links struct {
+	First string `json:"first,omitempty"`
+	Last  string `json:"last,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Self  string `json:"self,omitempty"`
+}
+
+// HasNext tells whether the server advertised a page following the current one.
+func (l *Links) HasNext() bool {
+	return l != nil && l.Next != ""
+}
+
 type AccountData struct {
 	Attributes     *AccountAttributes `json:"attributes,omitempty"`
 	ID             string             `json:"id,omitempty"`
@@ -13,20 +33,324 @@ type AccountData struct {
 }
 
 type AccountAttributes struct {
-	AccountClassification   *string  `json:"account_classification,omitempty"`
-	AccountMatchingOptOut   *bool    `json:"account_matching_opt_out,omitempty"`
-	AccountNumber           string   `json:"account_number,omitempty"`
-	AlternativeNames        []string `json:"alternative_names,omitempty"`
-	BankID                  string   `json:"bank_id,omitempty"`
-	BankIDCode              string   `json:"bank_id_code,omitempty"`
-	BaseCurrency            string   `json:"base_currency,omitempty"`
-	Bic                     string   `json:"bic,omitempty"`
-	Country                 *string  `json:"country,omitempty"`
-	CustomerId              string   `json:"customer_id,omitempty"`
-	Iban                    string   `json:"iban,omitempty"`
-	JointAccount            *bool    `json:"joint_account,omitempty"`
-	Name                    []string `json:"name,omitempty"`
-	SecondaryIdentification string   `json:"secondary_identification,omitempty"`
-	Status                  *string  `json:"status,omitempty"`
-	Switched                *bool    `json:"switched,omitempty"`
+	AcceptanceQualifier     *string            `json:"acceptance_qualifier,omitempty"`
+	AccountClassification   *string            `json:"account_classification,omitempty"`
+	AccountMatchingOptOut   *bool              `json:"account_matching_opt_out,omitempty"`
+	AccountNumber           string             `json:"account_number,omitempty"`
+	AlternativeNames        []string           `json:"alternative_names,omitempty"`
+	BankID                  string             `json:"bank_id,omitempty"`
+	BankIDCode              string             `json:"bank_id_code,omitempty"`
+	BaseCurrency            string             `json:"base_currency,omitempty"`
+	Bic                     string             `json:"bic,omitempty"`
+	Country                 *string            `json:"country,omitempty"`
+	CustomerId              string             `json:"customer_id,omitempty"`
+	Iban                    string             `json:"iban,omitempty"`
+	JointAccount            *bool              `json:"joint_account,omitempty"`
+	Name                    []string           `json:"name,omitempty"`
+	NameMatchingStatus      *string            `json:"name_matching_status,omitempty"`
+	ProcessingPurpose       *string            `json:"processing_purpose,omitempty"`
+	ReferenceMask           *string            `json:"reference_mask,omitempty"`
+	SecondaryIdentification string             `json:"secondary_identification,omitempty"`
+	Status                  *string            `json:"status,omitempty"`
+	Switched                *bool              `json:"switched,omitempty"`
+	UserDefinedData         []UserDefinedDatum `json:"user_defined_data,omitempty"`
+	ValidationType          *string            `json:"validation_type,omitempty"`
+}
+
+// UserDefinedDatum is a key-value pair of free-form data attached to an account by its owner.
+type UserDefinedDatum struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// AccountClassification is a value of the account_classification attribute.
+type AccountClassification string
+
+const (
+	ClassificationPersonal AccountClassification = "Personal"
+	ClassificationBusiness AccountClassification = "Business"
+)
+
+var accountClassifications = []AccountClassification{ClassificationPersonal, ClassificationBusiness}
+
+// Ptr returns the classification in the form AccountAttributes carries it.
+func (c AccountClassification) Ptr() *string {
+	value := string(c)
+	return &value
+}
+
+// Valid tells whether the classification is one the API accepts.
+func (c AccountClassification) Valid() bool {
+	for _, classification := range accountClassifications {
+		if c == classification {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountStatus is a value of the status attribute.
+type AccountStatus string
+
+const (
+	StatusPending   AccountStatus = "pending"
+	StatusConfirmed AccountStatus = "confirmed"
+	StatusFailed    AccountStatus = "failed"
+	StatusClosed    AccountStatus = "closed"
+)
+
+var accountStatuses = []AccountStatus{StatusPending, StatusConfirmed, StatusFailed, StatusClosed}
+
+// Ptr returns the status in the form AccountAttributes carries it.
+func (s AccountStatus) Ptr() *string {
+	value := string(s)
+	return &value
+}
+
+// Valid tells whether the status is one the API knows of.
+func (s AccountStatus) Valid() bool {
+	for _, status := range accountStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptanceQualifierValue returns the acceptance qualifier and whether it is set at all.
+// Like the other accessors it is safe to call on nil attributes.
+func (a *AccountAttributes) AcceptanceQualifierValue() (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	return valueOf(a.AcceptanceQualifier)
+}
+
+// AccountClassificationValue returns the account classification and whether it is set at all.
+func (a *AccountAttributes) AccountClassificationValue() (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	return valueOf(a.AccountClassification)
+}
+
+// AccountMatchingOptOutValue returns the account matching opt out flag and whether it is set at all.
+func (a *AccountAttributes) AccountMatchingOptOutValue() (bool, bool) {
+	if a == nil {
+		return false, false
+	}
+	return valueOf(a.AccountMatchingOptOut)
+}
+
+// CountryValue returns the country and whether it is set at all.
+func (a *AccountAttributes) CountryValue() (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	return valueOf(a.Country)
+}
+
+// JointAccountValue returns the joint account flag and whether it is set at all.
+func (a *AccountAttributes) JointAccountValue() (bool, bool) {
+	if a == nil {
+		return false, false
+	}
+	return valueOf(a.JointAccount)
+}
+
+// NameMatchingStatusValue returns the name matching status and whether it is set at all.
+func (a *AccountAttributes) NameMatchingStatusValue() (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	return valueOf(a.NameMatchingStatus)
+}
+
+// ProcessingPurposeValue returns the processing purpose and whether it is set at all.
+func (a *AccountAttributes) ProcessingPurposeValue() (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	return valueOf(a.ProcessingPurpose)
+}
+
+// ReferenceMaskValue returns the reference mask and whether it is set at all.
+func (a *AccountAttributes) ReferenceMaskValue() (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	return valueOf(a.ReferenceMask)
+}
+
+// StatusValue returns the account status and whether it is set at all.
+func (a *AccountAttributes) StatusValue() (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	return valueOf(a.Status)
+}
+
+// SwitchedValue returns the switched flag and whether it is set at all.
+func (a *AccountAttributes) SwitchedValue() (bool, bool) {
+	if a == nil {
+		return false, false
+	}
+	return valueOf(a.Switched)
+}
+
+// ValidationTypeValue returns the validation type and whether it is set at all.
+func (a *AccountAttributes) ValidationTypeValue() (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	return valueOf(a.ValidationType)
+}
+
+// Clone returns a deep copy of the account, sharing no pointer or slice with it,
+// so that either one can be modified without affecting the other.
+func (a *AccountData) Clone() *AccountData {
+	if a == nil {
+		return nil
+	}
+	clone := *a
+	clone.Version = copyOf(a.Version)
+	clone.Attributes = a.Attributes.Clone()
+	return &clone
+}
+
+// Clone returns a deep copy of the attributes, see AccountData.Clone.
+func (a *AccountAttributes) Clone() *AccountAttributes {
+	if a == nil {
+		return nil
+	}
+	clone := *a
+	clone.AcceptanceQualifier = copyOf(a.AcceptanceQualifier)
+	clone.AccountClassification = copyOf(a.AccountClassification)
+	clone.AccountMatchingOptOut = copyOf(a.AccountMatchingOptOut)
+	clone.AlternativeNames = copyOfSlice(a.AlternativeNames)
+	clone.Country = copyOf(a.Country)
+	clone.JointAccount = copyOf(a.JointAccount)
+	clone.Name = copyOfSlice(a.Name)
+	clone.NameMatchingStatus = copyOf(a.NameMatchingStatus)
+	clone.ProcessingPurpose = copyOf(a.ProcessingPurpose)
+	clone.ReferenceMask = copyOf(a.ReferenceMask)
+	clone.Status = copyOf(a.Status)
+	clone.Switched = copyOf(a.Switched)
+	clone.UserDefinedData = copyOfSlice(a.UserDefinedData)
+	clone.ValidationType = copyOf(a.ValidationType)
+	return &clone
+}
+
+func copyOf[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// copyOfSlice keeps nil slices nil and empty ones empty, the two serialize differently.
+func copyOfSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
+
+func valueOf[T any](p *T) (T, bool) {
+	if p == nil {
+		var zero T
+		return zero, false
+	}
+	return *p, true
+}
+
+// MergeAttributes returns a new AccountData that keeps base's identity and version,
+// with every set field of changes overlaid on top of base's attributes.
+// Pointer and slice fields are considered set when non-nil, plain strings when non-empty.
+// Neither base nor changes are modified, and the result shares no pointer or slice with them, see Clone.
+func MergeAttributes(base *AccountData, changes *AccountAttributes) *AccountData {
+	if base == nil {
+		return nil
+	}
+
+	merged := *base
+	merged.Version = copyOf(base.Version)
+
+	attributes := AccountAttributes{}
+	if base.Attributes != nil {
+		attributes = *base.Attributes.Clone()
+	}
+
+	if changes != nil {
+		if changes.AcceptanceQualifier != nil {
+			attributes.AcceptanceQualifier = copyOf(changes.AcceptanceQualifier)
+		}
+		if changes.AccountClassification != nil {
+			attributes.AccountClassification = copyOf(changes.AccountClassification)
+		}
+		if changes.AccountMatchingOptOut != nil {
+			attributes.AccountMatchingOptOut = copyOf(changes.AccountMatchingOptOut)
+		}
+		if changes.AccountNumber != "" {
+			attributes.AccountNumber = changes.AccountNumber
+		}
+		if changes.AlternativeNames != nil {
+			attributes.AlternativeNames = copyOfSlice(changes.AlternativeNames)
+		}
+		if changes.BankID != "" {
+			attributes.BankID = changes.BankID
+		}
+		if changes.BankIDCode != "" {
+			attributes.BankIDCode = changes.BankIDCode
+		}
+		if changes.BaseCurrency != "" {
+			attributes.BaseCurrency = changes.BaseCurrency
+		}
+		if changes.Bic != "" {
+			attributes.Bic = changes.Bic
+		}
+		if changes.Country != nil {
+			attributes.Country = copyOf(changes.Country)
+		}
+		if changes.CustomerId != "" {
+			attributes.CustomerId = changes.CustomerId
+		}
+		if changes.Iban != "" {
+			attributes.Iban = changes.Iban
+		}
+		if changes.JointAccount != nil {
+			attributes.JointAccount = copyOf(changes.JointAccount)
+		}
+		if changes.Name != nil {
+			attributes.Name = copyOfSlice(changes.Name)
+		}
+		if changes.NameMatchingStatus != nil {
+			attributes.NameMatchingStatus = copyOf(changes.NameMatchingStatus)
+		}
+		if changes.ProcessingPurpose != nil {
+			attributes.ProcessingPurpose = copyOf(changes.ProcessingPurpose)
+		}
+		if changes.ReferenceMask != nil {
+			attributes.ReferenceMask = copyOf(changes.ReferenceMask)
+		}
+		if changes.SecondaryIdentification != "" {
+			attributes.SecondaryIdentification = changes.SecondaryIdentification
+		}
+		if changes.Status != nil {
+			attributes.Status = copyOf(changes.Status)
+		}
+		if changes.Switched != nil {
+			attributes.Switched = copyOf(changes.Switched)
+		}
+		if changes.UserDefinedData != nil {
+			attributes.UserDefinedData = copyOfSlice(changes.UserDefinedData)
+		}
+		if changes.ValidationType != nil {
+			attributes.ValidationType = copyOf(changes.ValidationType)
+		}
+	}
+
+	merged.Attributes = &attributes
+	return &merged
 }
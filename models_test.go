@@ -0,0 +1,397 @@
+package interview_accountapi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMergeAttributes_OverlaysOnlyChangedFields(t *testing.T) {
+	country := "CA"
+	status := "pending"
+	jointAccount := true
+	version := int64(3)
+	base := &AccountData{
+		ID:             "0d209d7f-d07a-4542-947f-5885fddddae2",
+		OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43",
+		Type:           "accounts",
+		Version:        &version,
+		Attributes: &AccountAttributes{
+			AccountNumber: "41426819",
+			BankID:        "400300",
+			Bic:           "NWBKGB22",
+			Country:       &country,
+			JointAccount:  &jointAccount,
+			Name:          []string{"a", "b"},
+			Status:        &status,
+		},
+	}
+
+	newStatus := "confirmed"
+	merged := MergeAttributes(base, &AccountAttributes{
+		Bic:    "AAAAAABB",
+		Name:   []string{"x"},
+		Status: &newStatus,
+	})
+
+	assertAccountData(t, merged, &AccountData{
+		ID:             "0d209d7f-d07a-4542-947f-5885fddddae2",
+		OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43",
+		Type:           "accounts",
+		Version:        &version,
+		Attributes: &AccountAttributes{
+			AccountNumber: "41426819",
+			BankID:        "400300",
+			Bic:           "AAAAAABB",
+			Country:       &country,
+			JointAccount:  &jointAccount,
+			Name:          []string{"x"},
+			Status:        &newStatus,
+		},
+	})
+
+	// base must be left untouched
+	if base.Attributes.Bic != "NWBKGB22" || *base.Attributes.Status != "pending" {
+		t.Errorf("MergeAttributes must not modify the base account")
+	}
+	if merged == base || merged.Attributes == base.Attributes || merged.Version == base.Version {
+		t.Errorf("MergeAttributes must return a new account")
+	}
+}
+
+func TestMergeAttributes_BaseWithoutAttributes(t *testing.T) {
+	country := "GB"
+	merged := MergeAttributes(&AccountData{ID: "id1", Type: "accounts"}, &AccountAttributes{Country: &country})
+
+	assertAccountData(t, merged, &AccountData{
+		ID:         "id1",
+		Type:       "accounts",
+		Attributes: &AccountAttributes{Country: &country},
+	})
+}
+
+func TestMergeAttributes_NilChanges(t *testing.T) {
+	base := &AccountData{ID: "id1", Attributes: &AccountAttributes{Iban: "GB11NWBK40030041426819"}}
+	merged := MergeAttributes(base, nil)
+
+	assertAccountData(t, merged, base)
+	assertAccountData(t, MergeAttributes(nil, &AccountAttributes{}), nil)
+}
+
+func TestMergeAttributes_SharesNothing(t *testing.T) {
+	country, status := "GB", "pending"
+	version := int64(1)
+	base := &AccountData{
+		ID:         "id1",
+		Version:    &version,
+		Attributes: &AccountAttributes{Country: &country, Name: []string{"Jane Doe"}},
+	}
+	changes := &AccountAttributes{Status: &status, AlternativeNames: []string{"J. Doe"}}
+	merged := MergeAttributes(base, changes)
+
+	*merged.Version = 2
+	*merged.Attributes.Country = "FR"
+	merged.Attributes.Name[0] = "Mutated"
+	*merged.Attributes.Status = "closed"
+	merged.Attributes.AlternativeNames[0] = "Mutated"
+
+	if version != 1 || country != "GB" || base.Attributes.Name[0] != "Jane Doe" {
+		t.Errorf("Expecting base to be unaffected by changes to the result, got version=%d country=%s name=%s",
+			version, country, base.Attributes.Name[0])
+	}
+	if status != "pending" || changes.AlternativeNames[0] != "J. Doe" {
+		t.Errorf("Expecting changes to be unaffected by changes to the result, got status=%s alternative name=%s",
+			status, changes.AlternativeNames[0])
+	}
+}
+
+func TestAccountAttributes_Accessors(t *testing.T) {
+	class := "Personal"
+	optOut := false
+	country := "GB"
+	jointAccount := true
+	status := "confirmed"
+	switched := false
+	attributes := &AccountAttributes{
+		AccountClassification: &class,
+		AccountMatchingOptOut: &optOut,
+		Country:               &country,
+		JointAccount:          &jointAccount,
+		Status:                &status,
+		Switched:              &switched,
+	}
+
+	assertStringAccessor(t, "AccountClassification", "Personal", true)(attributes.AccountClassificationValue())
+	assertBoolAccessor(t, "AccountMatchingOptOut", false, true)(attributes.AccountMatchingOptOutValue())
+	assertStringAccessor(t, "Country", "GB", true)(attributes.CountryValue())
+	assertBoolAccessor(t, "JointAccount", true, true)(attributes.JointAccountValue())
+	assertStringAccessor(t, "Status", "confirmed", true)(attributes.StatusValue())
+	assertBoolAccessor(t, "Switched", false, true)(attributes.SwitchedValue())
+}
+
+func TestAccountAttributes_ExtendedAccessors(t *testing.T) {
+	acceptanceQualifier := "same_day"
+	nameMatchingStatus := "opted_out"
+	processingPurpose := "payments"
+	referenceMask := "############"
+	validationType := "card"
+	attributes := &AccountAttributes{
+		AcceptanceQualifier: &acceptanceQualifier,
+		NameMatchingStatus:  &nameMatchingStatus,
+		ProcessingPurpose:   &processingPurpose,
+		ReferenceMask:       &referenceMask,
+		ValidationType:      &validationType,
+	}
+
+	assertStringAccessor(t, "AcceptanceQualifier", "same_day", true)(attributes.AcceptanceQualifierValue())
+	assertStringAccessor(t, "NameMatchingStatus", "opted_out", true)(attributes.NameMatchingStatusValue())
+	assertStringAccessor(t, "ProcessingPurpose", "payments", true)(attributes.ProcessingPurposeValue())
+	assertStringAccessor(t, "ReferenceMask", "############", true)(attributes.ReferenceMaskValue())
+	assertStringAccessor(t, "ValidationType", "card", true)(attributes.ValidationTypeValue())
+}
+
+func TestAccountAttributes_AccessorsUnset(t *testing.T) {
+	for _, attributes := range []*AccountAttributes{{}, nil} {
+		assertStringAccessor(t, "AccountClassification", "", false)(attributes.AccountClassificationValue())
+		assertBoolAccessor(t, "AccountMatchingOptOut", false, false)(attributes.AccountMatchingOptOutValue())
+		assertStringAccessor(t, "Country", "", false)(attributes.CountryValue())
+		assertBoolAccessor(t, "JointAccount", false, false)(attributes.JointAccountValue())
+		assertStringAccessor(t, "Status", "", false)(attributes.StatusValue())
+		assertBoolAccessor(t, "Switched", false, false)(attributes.SwitchedValue())
+		assertStringAccessor(t, "AcceptanceQualifier", "", false)(attributes.AcceptanceQualifierValue())
+		assertStringAccessor(t, "NameMatchingStatus", "", false)(attributes.NameMatchingStatusValue())
+		assertStringAccessor(t, "ProcessingPurpose", "", false)(attributes.ProcessingPurposeValue())
+		assertStringAccessor(t, "ReferenceMask", "", false)(attributes.ReferenceMaskValue())
+		assertStringAccessor(t, "ValidationType", "", false)(attributes.ValidationTypeValue())
+	}
+}
+
+func assertStringAccessor(t *testing.T, name string, expected string, expectedPresent bool) func(string, bool) {
+	return func(actual string, present bool) {
+		if actual != expected || present != expectedPresent {
+			t.Errorf("%s accessor doesn't match, expected=(%s, %t), got=(%s, %t)", name, expected, expectedPresent, actual, present)
+		}
+	}
+}
+
+func assertBoolAccessor(t *testing.T, name string, expected bool, expectedPresent bool) func(bool, bool) {
+	return func(actual bool, present bool) {
+		if actual != expected || present != expectedPresent {
+			t.Errorf("%s accessor doesn't match, expected=(%t, %t), got=(%t, %t)", name, expected, expectedPresent, actual, present)
+		}
+	}
+}
+
+func TestClone_MutatingCloneLeavesOriginalUntouched(t *testing.T) {
+	original := NewAccountDataBuilder().
+		WithID("0d209d7f-d07a-4542-947f-5885fddddae2").
+		WithVersion(1).
+		WithCountry("GB").
+		WithAlternativeNames("x", "y").
+		WithSwitched(false).
+		Build()
+	snapshot := NewAccountDataBuilder().
+		WithID("0d209d7f-d07a-4542-947f-5885fddddae2").
+		WithVersion(1).
+		WithCountry("GB").
+		WithAlternativeNames("x", "y").
+		WithSwitched(false).
+		Build()
+
+	clone := original.Clone()
+	assertAccountData(t, clone, original)
+
+	clone.Attributes.AlternativeNames[0] = "changed"
+	*clone.Attributes.Country = "CA"
+	*clone.Attributes.Switched = true
+	*clone.Version = 2
+	clone.Attributes.Name = append(clone.Attributes.Name, "new")
+
+	assertAccountData(t, original, snapshot)
+}
+
+func TestClone_KeepsNilAndEmptyApart(t *testing.T) {
+	original := &AccountData{Attributes: &AccountAttributes{AlternativeNames: []string{}}}
+	clone := original.Clone()
+
+	if clone.Attributes.AlternativeNames == nil || clone.Attributes.Name != nil {
+		t.Errorf("Expecting an empty AlternativeNames and a nil Name, got=%#v and %#v",
+			clone.Attributes.AlternativeNames, clone.Attributes.Name)
+	}
+	if clone.Version != nil || clone.Attributes.Country != nil {
+		t.Errorf("Expecting nil pointers to stay nil")
+	}
+
+	var nilAccount *AccountData
+	if nilAccount.Clone() != nil {
+		t.Errorf("Expecting the clone of nil to be nil")
+	}
+}
+
+func TestDeserializeToListEnvelope_WithMeta(t *testing.T) {
+	payload := []byte(`{"data":[{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}],
+		"links":{"self":"/v1/organisation/accounts"},"meta":{"total":1}}`)
+	envelope, httpErr := defaultDeserializingClient().deserializeToListEnvelope(&payload)
+
+	assertHttpError(t, httpErr, nil)
+	if len(envelope.Data) != 1 || envelope.Data[0].ID != "0d209d7f-d07a-4542-947f-5885fddddae2" {
+		t.Errorf("Expecting a single account, got=%v", envelope.Data)
+	}
+	if envelope.Links.Self != "/v1/organisation/accounts" {
+		t.Errorf("Expecting the self link, got=%s", envelope.Links.Self)
+	}
+	if envelope.Meta["total"] != float64(1) {
+		t.Errorf("Expecting meta total=1, got=%v", envelope.Meta["total"])
+	}
+}
+
+func TestDeserializeToListEnvelope_SingleObject(t *testing.T) {
+	payload := []byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`)
+	envelope, httpErr := defaultDeserializingClient().deserializeToListEnvelope(&payload)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:           errors.New("expecting data to be an array, got an object"),
+		Message:         "Error deserializing json",
+		Kind:            KindSerialization,
+		ResponsePayload: &payload,
+	})
+	if envelope != nil {
+		t.Errorf("Expecting no envelope")
+	}
+}
+
+func TestDeserializeToResponseEnvelope_List(t *testing.T) {
+	payload := []byte(`{"data":[{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}]}`)
+	envelope, httpErr := defaultDeserializingClient().deserializeToResponseEnvelope(&payload)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:           errors.New("expecting data to be an object, got an array"),
+		Message:         "Error deserializing json",
+		Kind:            KindSerialization,
+		ResponsePayload: &payload,
+	})
+	if envelope != nil {
+		t.Errorf("Expecting no envelope")
+	}
+}
+
+func TestDeserializeToResponseEnvelope_Scalar(t *testing.T) {
+	payload := []byte(`{"data":"0d209d7f-d07a-4542-947f-5885fddddae2"}`)
+	envelope, httpErr := defaultDeserializingClient().deserializeToResponseEnvelope(&payload)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:           errors.New("expecting data to be an object, got a scalar"),
+		Message:         "Error deserializing json",
+		Kind:            KindSerialization,
+		ResponsePayload: &payload,
+	})
+	if envelope != nil {
+		t.Errorf("Expecting no envelope")
+	}
+}
+
+func TestDeserializeToResponseEnvelope_Null(t *testing.T) {
+	payload := []byte(`null`)
+	envelope, httpErr := defaultDeserializingClient().deserializeToResponseEnvelope(&payload)
+	assertHttpError(t, httpErr, nil)
+
+	account, httpErr := accountDataOrError(envelope, &payload)
+	assertHttpError(t, httpErr, &HTTPError{
+		Message:         "Got an empty object after deserialization, json payload was an empty object?",
+		Kind:            KindSerialization,
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func defaultDeserializingClient() *httpAccountsClientImpl {
+	return &httpAccountsClientImpl{deserialize: json.Unmarshal}
+}
+
+func TestMarshal_MinimalAccountOmitsEmptyFields(t *testing.T) {
+	data, _ := json.Marshal(Envelope[AccountData]{Data: &AccountData{
+		ID:         "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Attributes: &AccountAttributes{},
+	}})
+
+	expected := `{"data":{"attributes":{},"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`
+	if string(data) != expected {
+		t.Errorf("Expecting payload=%s, got=%s", expected, data)
+	}
+}
+
+func TestMarshal_PopulatedAccountKeepsEveryField(t *testing.T) {
+	account := NewAccountDataBuilder().
+		WithID("0d209d7f-d07a-4542-947f-5885fddddae2").
+		WithOrganisationID("ba61483c-d5c5-4f50-ae81-6b8c039bea43").
+		WithAccountClassification("Personal").
+		WithAccountMatchingOptOut(false).
+		WithAccountNumber("41426819").
+		WithAlternativeNames("Sam Holder").
+		WithBankID("400300").
+		WithBankIDCode("GBDSC").
+		WithBaseCurrency("GBP").
+		WithBic("NWBKGB22").
+		WithCountry("GB").
+		WithCustomerId("123").
+		WithIban("GB11NWBK40030041426819").
+		WithJointAccount(false).
+		WithName("Samantha Holder").
+		WithSecondaryIdentification("A1B2C3D4").
+		WithStatus("confirmed").
+		WithSwitched(false).
+		Build()
+	data, _ := json.Marshal(account)
+
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+	attributes := fields["attributes"].(map[string]any)
+	for _, key := range []string{"account_classification", "account_matching_opt_out", "account_number",
+		"alternative_names", "bank_id", "bank_id_code", "base_currency", "bic", "country", "customer_id", "iban",
+		"joint_account", "name", "secondary_identification", "status", "switched"} {
+		if _, ok := attributes[key]; !ok {
+			t.Errorf("Expecting %s to be serialized, got=%s", key, data)
+		}
+	}
+	if err := account.Validate(); err != nil {
+		t.Errorf("Expecting the account to survive a round trip, got=%v", err)
+	}
+}
+
+func TestAccountClassification_Valid(t *testing.T) {
+	tests := map[AccountClassification]bool{
+		ClassificationPersonal:      true,
+		ClassificationBusiness:      true,
+		"personal":                  false,
+		"Unexpected Classification": false,
+		"":                          false,
+	}
+	for classification, expected := range tests {
+		if classification.Valid() != expected {
+			t.Errorf("Expecting %q valid=%t", classification, expected)
+		}
+	}
+}
+
+func TestAccountStatus_Valid(t *testing.T) {
+	tests := map[AccountStatus]bool{
+		StatusPending:   true,
+		StatusConfirmed: true,
+		StatusFailed:    true,
+		StatusClosed:    true,
+		"Pending":       false,
+		"":              false,
+	}
+	for status, expected := range tests {
+		if status.Valid() != expected {
+			t.Errorf("Expecting %q valid=%t", status, expected)
+		}
+	}
+}
+
+func TestAccountClassification_Ptr(t *testing.T) {
+	first, second := ClassificationPersonal.Ptr(), ClassificationPersonal.Ptr()
+	if *first != "Personal" || first == second {
+		t.Errorf("Expecting distinct pointers to Personal, got=%v and %v", first, second)
+	}
+}
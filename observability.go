@@ -0,0 +1,73 @@
+package interview_accountapi
+
+import (
+	"net/http"
+	"time"
+)
+
+// observation is what gets recorded of a request sent, gathered once and handed to the tracer, the logger
+// and the metrics alike, so that the telemetry of a single request tells the same story in all three.
+type observation struct {
+	req         *http.Request
+	resp        *http.Response
+	err         error
+	elapsed     time.Duration
+	fingerprint string
+	// requestID is the X-Request-Id the request was sent with or else the one the response carries, if any
+	requestID string
+}
+
+// status is the status code of the response, 0 when no response was received at all.
+func (o *observation) status() int {
+	if o.resp == nil {
+		return 0
+	}
+	return o.resp.StatusCode
+}
+
+// WithResponseObservabilityContext correlates the telemetry of every request by its request id: requests are sent
+// with an X-Request-Id as with WithAutoRequestID, which is set as the "request.id" attribute of the operation's span,
+// logged along with the request, see WithLogger, and handed to the metrics if they implement RequestIDMetrics.
+// A single failed request can thus be followed across traces, logs and metrics.
+func WithResponseObservabilityContext() ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		hac.autoRequestID = true
+		hac.correlate = true
+		return nil
+	}
+}
+
+// observed wraps the request invoker so that every request going through it is traced, metered and, with WithLogger,
+// logged. It is the single place the telemetry of requests is recorded, for all operations.
+func (hac *httpAccountsClientImpl) observed(doRequest DoRequest) DoRequest {
+	return func(req *http.Request) (*http.Response, error) {
+		var fingerprint string
+		if hac.logging {
+			// fingerprinting takes a copy of the body, only worth it for lines someone is going to read
+			fingerprint = RequestFingerprint(req.Method, req.URL.String(), requestBody(req))
+		}
+		if hac.byteMetrics != nil {
+			req = hac.countingSent(req)
+		}
+		start := hac.clock.Now()
+		resp, err := doRequest(req)
+		o := &observation{
+			req:         req,
+			resp:        resp,
+			err:         err,
+			elapsed:     hac.clock.Now().Sub(start),
+			fingerprint: fingerprint,
+			requestID:   req.Header.Get(requestID),
+		}
+		if o.requestID == "" && resp != nil {
+			o.requestID = resp.Header.Get(requestID)
+		}
+
+		hac.traceRequest(o)
+		hac.meterRequest(o)
+		if hac.logging {
+			hac.logRequest(o)
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,58 @@
+package interview_accountapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithResponseObservabilityContext_CorrelatesTelemetry(t *testing.T) {
+	var sent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("X-Request-Id")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	logger, tracer, metrics := &capturingLogger{}, &inMemoryTracer{}, newInMemoryMetrics()
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL,
+		WithLogger(logger), WithTracer(tracer), WithMetrics(metrics), WithResponseObservabilityContext())
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	if httpErr == nil || httpErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Expecting the fetch to fail with a 500, got=%v", httpErr)
+	}
+	if sent == "" {
+		t.Fatalf("Expecting the request to be sent with a request id")
+	}
+	if len(tracer.spans) != 1 || tracer.spans[0].attributes["request.id"] != sent {
+		t.Errorf("Expecting the span to carry request id %s, got=%v", sent, tracer.spans)
+	}
+	if len(logger.debugs) != 1 || !strings.HasSuffix(logger.debugs[0], ", request id "+sent) {
+		t.Errorf("Expecting the log line to carry request id %s, got=%v", sent, logger.debugs)
+	}
+	if len(metrics.ids) != 1 || metrics.ids[0] != "GET 500 "+sent {
+		t.Errorf("Expecting the metrics to carry request id %s, got=%v", sent, metrics.ids)
+	}
+}
+
+func TestWithResponseObservabilityContext_Disabled(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	tracer, metrics := &inMemoryTracer{}, newInMemoryMetrics()
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithTracer(tracer), WithMetrics(metrics), WithAutoRequestID())
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	if _, ok := tracer.spans[0].attributes["request.id"]; ok {
+		t.Errorf("Expecting no request id on the span, got=%v", tracer.spans[0].attributes)
+	}
+	if len(metrics.ids) != 0 {
+		t.Errorf("Expecting no request ids in the metrics, got=%v", metrics.ids)
+	}
+}
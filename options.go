@@ -0,0 +1,433 @@
+package interview_accountapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var apiVersionPattern = regexp.MustCompile(`^v\d+$`)
+
+// ClientOption tweaks the client built by AccountsHttpClientFactory.
+// Options are applied in the order they are passed, an option returning an error
+// aborts client construction and the error is handed back to the caller of the factory.
+type ClientOption func(*httpAccountsClientImpl) error
+
+// WithTimeout limits the time a single Http request may take, including connection time,
+// any redirects and reading the response body.
+// By default there is no timeout, so a hung server would block the caller indefinitely
+// unless the context passed to the operation carries a deadline.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if d <= 0 {
+			return errors.New("timeout must be positive")
+		}
+		hac.timeout = d
+		return nil
+	}
+}
+
+// WithAuthToken authenticates every request with the given bearer token,
+// i.e. sends an "Authorization: Bearer <token>" header.
+func WithAuthToken(token string) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if token == "" {
+			return errors.New("auth token must not be empty")
+		}
+		hac.headers.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+// WithHeader adds a static header sent along with every request, e.g. an API key.
+// Setting the same key more than once replaces the previous value.
+func WithHeader(key, value string) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if key == "" {
+			return errors.New("header key must not be empty")
+		}
+		hac.headers.Set(key, value)
+		return nil
+	}
+}
+
+// WithRequiredSchemaVersion makes the client refuse successful responses whose X-Schema-Version header
+// doesn't match the given version, a missing header counts as a mismatch.
+// This prevents silently consuming payloads of an incompatible schema.
+func WithRequiredSchemaVersion(v string) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if v == "" {
+			return errors.New("schema version must not be empty")
+		}
+		hac.requiredSchemaVersion = v
+		return nil
+	}
+}
+
+// WithSkipNoopUpdates makes Update fetch the account first and skip the PATCH altogether when applying
+// the update would not change any attribute, returning the fetched account instead.
+// The PATCH is still sent when the fetched version differs from the one passed to Update,
+// so that the server gets to report the version conflict as usual.
+func WithSkipNoopUpdates() ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		hac.skipNoopUpdates = true
+		return nil
+	}
+}
+
+// WithClientSideValidation makes Create check the account before sending it,
+// so that a missing required field, see AccountData.RequireFields, or obviously malformed values,
+// e.g. an IBAN failing its checksum, a malformed BIC or an unknown country code, are rejected
+// with a KindValidation error without a round trip to the server.
+func WithClientSideValidation() ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		hac.clientSideValidation = true
+		return nil
+	}
+}
+
+// WithAPIVersion targets another version of the accounts API, e.g. "v2" for v2/organisation/accounts.
+// The version must be a "v" followed by a number, "v1" is used by default.
+func WithAPIVersion(version string) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if !apiVersionPattern.MatchString(version) {
+			return errors.New("api version must be a v followed by a number, e.g. v1")
+		}
+		hac.apiVersion = version
+		return nil
+	}
+}
+
+// WithDefaultOrganisationID fills in the OrganisationID of accounts created without one,
+// for callers working within a single organisation. An OrganisationID set on the account takes precedence.
+func WithDefaultOrganisationID(id string) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if !isValidUUID(id) {
+			return errors.New("default organisation id must be a valid uuid")
+		}
+		hac.defaultOrgID = id
+		return nil
+	}
+}
+
+// WithBasePath prefixes the accounts API path, for when the API is mounted under a sub-path of the host,
+// e.g. with base path "form3/api" accounts are looked up at <host>/form3/api/v1/organisation/accounts.
+func WithBasePath(path string) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		path = strings.Trim(path, "/")
+		if path == "" {
+			return errors.New("base path must not be empty")
+		}
+		hac.basePath = path
+		return nil
+	}
+}
+
+// WithHealthPath points HealthCheck at another path of the host, e.g. "/health" for older API versions.
+// A missing leading slash is added.
+func WithHealthPath(path string) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if path == "" {
+			return errors.New("health path must not be empty")
+		}
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		hac.healthPath = path
+		return nil
+	}
+}
+
+// WithHTTPClient sends requests through the given client instead of a default one, e.g. to plug in a custom
+// transport for TLS settings, proxies or connection pooling. The client is copied, and so is its transport
+// when options like WithProxy tweak it, so that they don't affect the caller's client, whatever their order.
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if c == nil {
+			return errors.New("http client must not be nil")
+		}
+		copied := *c
+		hac.client = &copied
+		return nil
+	}
+}
+
+// WithProxy sends requests through the given proxy, e.g. "http://proxy.corp:3128", instead of the one
+// configured in the environment, if any.
+// The proxy is set on a copy of the client's transport, which must be an *http.Transport.
+func WithProxy(proxyURL string) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if err := validateUrl(proxyURL); err != nil {
+			return errors.New("invalid proxy URL provided")
+		}
+		proxy, _ := url.Parse(proxyURL)
+		hac.transportSettings = append(hac.transportSettings, func(transport *http.Transport) {
+			transport.Proxy = http.ProxyURL(proxy)
+		})
+		return nil
+	}
+}
+
+// WithMaxIdleConns caps the number of idle connections kept open across all hosts, see http.Transport.MaxIdleConns.
+// Like WithProxy, it sets it on a copy of the client's transport, which must be an *http.Transport.
+func WithMaxIdleConns(n int) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if n <= 0 {
+			return errors.New("max idle conns must be positive")
+		}
+		hac.transportSettings = append(hac.transportSettings, func(transport *http.Transport) {
+			transport.MaxIdleConns = n
+		})
+		return nil
+	}
+}
+
+// WithMaxIdleConnsPerHost caps the number of idle connections kept open to the API host,
+// which defaults to http.DefaultMaxIdleConnsPerHost, i.e. 2, too few for clients placing many requests at once.
+// Like WithProxy, it sets it on a copy of the client's transport, which must be an *http.Transport.
+func WithMaxIdleConnsPerHost(n int) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if n <= 0 {
+			return errors.New("max idle conns per host must be positive")
+		}
+		hac.transportSettings = append(hac.transportSettings, func(transport *http.Transport) {
+			transport.MaxIdleConnsPerHost = n
+		})
+		return nil
+	}
+}
+
+// WithRoundTripper wraps the client's transport in the given middleware, e.g. to sign requests or to record them,
+// for anything the other options don't cover. The middleware gets the transport as configured by the other options,
+// whatever their order. Wrappers passed in several calls are chained in order, the first one being the outermost,
+// i.e. the first to see a request and the last to see its response.
+func WithRoundTripper(wrap func(http.RoundTripper) http.RoundTripper) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if wrap == nil {
+			return errors.New("round tripper wrapper must not be nil")
+		}
+		hac.roundTrippers = append(hac.roundTrippers, wrap)
+		return nil
+	}
+}
+
+// wrapTransport applies the middleware given in WithRoundTripper, innermost first.
+func (hac *httpAccountsClientImpl) wrapTransport() {
+	if len(hac.roundTrippers) == 0 {
+		return
+	}
+	transport := hac.client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(hac.roundTrippers) - 1; i >= 0; i-- {
+		transport = hac.roundTrippers[i](transport)
+	}
+	hac.client.Transport = transport
+}
+
+// WithResponseInterceptor calls intercept with every response the client receives, retried attempts included,
+// before the body gets read, e.g. to record status codes and headers. intercept gets a copy of the response
+// without its body, which is left for the client to consume. It is called concurrently when the client is.
+func WithResponseInterceptor(intercept func(*http.Response)) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if intercept == nil {
+			return errors.New("response interceptor must not be nil")
+		}
+		hac.interceptResponse = intercept
+		return nil
+	}
+}
+
+// intercepted wraps the request invoker so that every response going through it is shown to the interceptor.
+func (hac *httpAccountsClientImpl) intercepted(doRequest DoRequest) DoRequest {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := doRequest(req)
+		if resp != nil {
+			bodiless := *resp
+			bodiless.Body = http.NoBody
+			hac.interceptResponse(&bodiless)
+		}
+		return resp, err
+	}
+}
+
+// configureClient applies the settings of the options tweaking the http.Client once all options have run,
+// so that they land on the client in use even when WithHTTPClient replaces it after them.
+func (hac *httpAccountsClientImpl) configureClient() error {
+	if hac.timeout > 0 {
+		hac.client.Timeout = hac.timeout
+	}
+	if hac.reportContentLength && hac.metrics != nil {
+		contentLengths, ok := hac.metrics.(ContentLengthMetrics)
+		if !ok {
+			return errors.New("metrics must implement ContentLengthMetrics to report response content lengths")
+		}
+		hac.contentLengths = contentLengths
+	}
+	if hac.reportBytes && hac.metrics != nil {
+		byteMetrics, ok := hac.metrics.(ByteMetrics)
+		if !ok {
+			return errors.New("metrics must implement ByteMetrics to report body sizes")
+		}
+		hac.byteMetrics = byteMetrics
+	}
+	if len(hac.transportSettings) == 0 {
+		return nil
+	}
+	transport, err := hac.ownTransport()
+	if err != nil {
+		return err
+	}
+	for _, apply := range hac.transportSettings {
+		apply(transport)
+	}
+	return nil
+}
+
+// ownTransport returns the transport of the client, making sure it is one the client can tweak without affecting
+// anyone else: the default transport and transports of clients passed in WithHTTPClient get cloned first.
+func (hac *httpAccountsClientImpl) ownTransport() (*http.Transport, error) {
+	if hac.ownedTransport != nil && hac.client.Transport == hac.ownedTransport {
+		return hac.ownedTransport, nil
+	}
+
+	base := hac.client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return nil, errors.New("transport must be an *http.Transport to be configured")
+	}
+	hac.ownedTransport = transport.Clone()
+	hac.client.Transport = hac.ownedTransport
+	return hac.ownedTransport, nil
+}
+
+// WithUserAgent identifies the client's traffic with the given User-Agent header,
+// an empty user agent leaves the default one in place, i.e. "interview-accountapi/1.0 (+github.com/imochurad)".
+func WithUserAgent(userAgent string) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		hac.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithAutoRequestID tags every request with a freshly generated X-Request-Id header, unless one is set with WithHeader,
+// so that it can be traced through the server's logs. The id is logged along with the request, see WithLogger.
+func WithAutoRequestID() ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		hac.autoRequestID = true
+		return nil
+	}
+}
+
+// WithMaxResponseBytes caps the size of the response bodies the client is willing to read, a larger body fails
+// the operation with a "response body exceeded max size" error rather than exhausting memory.
+// Gzipped bodies are capped once inflated as well. The default cap is 10MB.
+func WithMaxResponseBytes(n int64) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if n <= 0 {
+			return errors.New("max response bytes must be positive")
+		}
+		hac.maxResponseBytes = n
+		return nil
+	}
+}
+
+// WithSuccessStatus accepts status codes beyond the ones the accounts API documents as successful,
+// e.g. a gateway answering creates with 202 along with the created account.
+// The predicate is asked about every status code other than the documented one, along with the operation,
+// named as in error messages: "Get" for Fetch, "Head", "Post" for Create, "Patch" for Update, "Delete" and "List".
+// A 202 to a Create the predicate accepts is deserialized as is, rather than polled for, see Create.
+func WithSuccessStatus(predicate func(operation string, code int) bool) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if predicate == nil {
+			return errors.New("success status predicate must not be nil")
+		}
+		hac.successStatus = predicate
+		return nil
+	}
+}
+
+// WithSerializer encodes request payloads with the given function rather than json.Marshal, e.g. to enforce
+// a naming policy of a server variant. Fields left empty are omitted by the json tags of the models,
+// a serializer honouring the tags keeps them out of the payload.
+func WithSerializer(serialize Serialize) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if serialize == nil {
+			return errors.New("serializer must not be nil")
+		}
+		hac.serialize = serialize
+		return nil
+	}
+}
+
+// WithMarshalIndent indents request payloads, which makes them easier to read when debugging, e.g. in body logs.
+func WithMarshalIndent() ClientOption {
+	return WithSerializer(func(v any) ([]byte, error) {
+		return json.MarshalIndent(v, "", "  ")
+	})
+}
+
+// WithDeserializer decodes response payloads with the given function rather than json.Unmarshal,
+// e.g. to plug in a faster json library. It has to honour the json tags of the models.
+func WithDeserializer(deserialize Deserialize) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if deserialize == nil {
+			return errors.New("deserializer must not be nil")
+		}
+		hac.deserialize = deserialize
+		return nil
+	}
+}
+
+// WithFetchNormalizer hands every account returned by Fetch, its variants and List, ListFiltered and ListAll
+// to normalize before the caller gets it, e.g. to upper case the BIC of gateways returning it in lower case.
+// Accounts are normalized before being cached, see WithFetchCache. Failed operations don't call it.
+func WithFetchNormalizer(normalize func(*AccountData)) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if normalize == nil {
+			return errors.New("fetch normalizer must not be nil")
+		}
+		hac.normalizeFetched = normalize
+		return nil
+	}
+}
+
+// normalize applies the normalizer given in WithFetchNormalizer, if any, to accounts.
+func (hac *httpAccountsClientImpl) normalize(accounts ...*AccountData) {
+	if hac.normalizeFetched == nil {
+		return
+	}
+	for _, account := range accounts {
+		if account != nil {
+			hac.normalizeFetched(account)
+		}
+	}
+}
+
+// WithAutoPaginate makes List called with a page number and size both 0, which would otherwise be rejected,
+// follow every page as ListAll does and return all the accounts in one slice. It gives up with an HTTPError
+// after 1000 pages. List called with explicit page parameters keeps returning a single page.
+func WithAutoPaginate() ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		hac.autoPaginate = true
+		return nil
+	}
+}
+
+// WithDeleteVerification makes Delete fetch the account after the server answered with a 204, and fail with an HTTPError
+// unless the fetch yields a 404, catching backends that are eventually consistent or acknowledge deletes they didn't do.
+// It costs a round trip per delete, so it is off by default. Failing to fetch is reported as is.
+func WithDeleteVerification() ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		hac.verifyDelete = true
+		return nil
+	}
+}
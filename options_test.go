@@ -0,0 +1,1105 @@
+package interview_accountapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout_NotPositive(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	client, err := clientFactory.MakeClient("http://localhost:8080", WithTimeout(0))
+
+	if err == nil || err.Error() != "timeout must be positive" {
+		t.Errorf("Expecting timeout validation error, got=%v", err)
+	}
+	if client != nil {
+		t.Errorf("Expecting client to be nil")
+	}
+}
+
+func TestWithTimeout_SlowServer(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-unblock:
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithTimeout(50*time.Millisecond))
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Fetch(context.Background(), id.String())
+
+	if httpErr == nil || httpErr.Cause == nil {
+		t.Fatalf("Expecting http error with a cause")
+	}
+	if !strings.Contains(httpErr.Cause.Error(), "Client.Timeout exceeded") {
+		t.Errorf("Expecting the cause to mention the client timeout, got=%s", httpErr.Cause.Error())
+	}
+	assertAccountData(t, account, nil)
+}
+
+func TestWithAuthToken_Empty(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithAuthToken(""))
+
+	if err == nil || err.Error() != "auth token must not be empty" {
+		t.Errorf("Expecting auth token validation error, got=%v", err)
+	}
+}
+
+func TestWithHeader_EmptyKey(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithHeader("", "value"))
+
+	if err == nil || err.Error() != "header key must not be empty" {
+		t.Errorf("Expecting header key validation error, got=%v", err)
+	}
+}
+
+func TestWithAuthTokenAndHeader_SentOnEveryOperation(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			t.Errorf("%s: unexpected Authorization header, got=%s", r.Method, r.Header.Get("Authorization"))
+		}
+		if r.Header.Get("X-Api-Key") != "key-123" {
+			t.Errorf("%s: unexpected X-Api-Key header, got=%s", r.Method, r.Header.Get("X-Api-Key"))
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+		case http.MethodPost:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithAuthToken("s3cr3t"), WithHeader("X-Api-Key", "key-123"))
+	id := "0d209d7f-d07a-4542-947f-5885fddddae2"
+
+	_, httpErr := client.Fetch(context.Background(), id)
+	assertHttpError(t, httpErr, nil)
+
+	_, httpErr = client.Create(context.Background(), &AccountData{ID: id})
+	assertHttpError(t, httpErr, nil)
+
+	httpErr = client.Delete(context.Background(), id, 0)
+	assertHttpError(t, httpErr, nil)
+
+	if requests != 3 {
+		t.Errorf("Expecting 3 requests, got=%d", requests)
+	}
+}
+
+func TestWithRequiredSchemaVersion_Matching(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Schema-Version", "2")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRequiredSchemaVersion("2"))
+	account, header, httpErr := client.FetchWithResponse(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+	if header.Get("X-Schema-Version") != "2" {
+		t.Errorf("Expecting the schema version to be exposed in the headers, got=%s", header.Get("X-Schema-Version"))
+	}
+}
+
+func TestWithRequiredSchemaVersion_Mismatching(t *testing.T) {
+	payload := []byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Schema-Version", "1")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRequiredSchemaVersion("2"))
+
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      200,
+		Status:          "200 OK",
+		Message:         "Unexpected X-Schema-Version, expecting 2, got 1",
+		Kind:            KindSerialization,
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+
+	account, httpErr = client.Create(context.Background(), &AccountData{})
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      201,
+		Status:          "201 Created",
+		Message:         "Unexpected X-Schema-Version, expecting 2, got 1",
+		Kind:            KindSerialization,
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestWithRequiredSchemaVersion_MissingHeader(t *testing.T) {
+	payload := []byte(`{"data":[]}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRequiredSchemaVersion("2"))
+	accounts, _, httpErr := client.List(context.Background(), 0, 10)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      200,
+		Status:          "200 OK",
+		Message:         "Unexpected X-Schema-Version, expecting 2, got ",
+		Kind:            KindSerialization,
+		ResponsePayload: &payload,
+	})
+	if accounts != nil {
+		t.Errorf("Expecting accounts to be nil")
+	}
+}
+
+func newNoopUpdateServer(t *testing.T, patches *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodPatch {
+			*patches++
+			w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","version":3,
+				"attributes":{"status":"closed","bank_id":"400300"}}}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","version":2,
+			"attributes":{"status":"confirmed","bank_id":"400300"}}}`))
+	}))
+}
+
+func TestWithSkipNoopUpdates_IdenticalUpdateSkipped(t *testing.T) {
+	patches := 0
+	server := newNoopUpdateServer(t, &patches)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithSkipNoopUpdates())
+	status := "confirmed"
+	account, httpErr := client.Update(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 2,
+		&AccountData{Attributes: &AccountAttributes{Status: &status}})
+
+	version := int64(2)
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{
+		ID:         "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Version:    &version,
+		Attributes: &AccountAttributes{Status: &status, BankID: "400300"},
+	})
+	if patches != 0 {
+		t.Errorf("Expecting the no-op update to be skipped, got %d patches", patches)
+	}
+}
+
+func TestWithSkipNoopUpdates_DifferingUpdateProceeds(t *testing.T) {
+	patches := 0
+	server := newNoopUpdateServer(t, &patches)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithSkipNoopUpdates())
+	status := "closed"
+	account, httpErr := client.Update(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 2,
+		&AccountData{Attributes: &AccountAttributes{Status: &status}})
+
+	version := int64(3)
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{
+		ID:         "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Version:    &version,
+		Attributes: &AccountAttributes{Status: &status, BankID: "400300"},
+	})
+	if patches != 1 {
+		t.Errorf("Expecting a single patch, got=%d", patches)
+	}
+}
+
+func TestWithSkipNoopUpdates_StaleVersionProceeds(t *testing.T) {
+	patches := 0
+	server := newNoopUpdateServer(t, &patches)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithSkipNoopUpdates())
+	status := "confirmed"
+	// the account moved on to version 2 since the caller last saw it, the server has to decide
+	client.Update(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 1,
+		&AccountData{Attributes: &AccountAttributes{Status: &status}})
+
+	if patches != 1 {
+		t.Errorf("Expecting a single patch, got=%d", patches)
+	}
+}
+
+func TestWithAPIVersionAndBasePath(t *testing.T) {
+	path := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithAPIVersion("v2"), WithBasePath("/form3/api/"))
+	httpErr := client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	assertHttpError(t, httpErr, nil)
+	expected := "/form3/api/v2/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2"
+	if path != expected {
+		t.Errorf("Expecting path=%s, got=%s", expected, path)
+	}
+}
+
+func TestWithAPIVersion_Invalid(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	for _, version := range []string{"", "2", "v", "V2", "v2beta", "/v2"} {
+		_, err := clientFactory.MakeClient("http://localhost:8080", WithAPIVersion(version))
+		if err == nil || err.Error() != "api version must be a v followed by a number, e.g. v1" {
+			t.Errorf("Expecting api version %q to be rejected, got=%v", version, err)
+		}
+	}
+}
+
+func TestWithBasePath_Empty(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	for _, path := range []string{"", "/", "//"} {
+		_, err := clientFactory.MakeClient("http://localhost:8080", WithBasePath(path))
+		if err == nil || err.Error() != "base path must not be empty" {
+			t.Errorf("Expecting base path %q to be rejected, got=%v", path, err)
+		}
+	}
+}
+
+func TestWithHealthPath(t *testing.T) {
+	path := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithHealthPath("health"))
+	httpErr := client.HealthCheck(context.Background())
+
+	assertHttpError(t, httpErr, nil)
+	if path != "/health" {
+		t.Errorf("Expecting path=/health, got=%s", path)
+	}
+
+	_, err := clientFactory.MakeClient(server.URL, WithHealthPath(""))
+	if err == nil || err.Error() != "health path must not be empty" {
+		t.Errorf("Expecting health path validation error, got=%v", err)
+	}
+}
+
+type countingTransport struct {
+	roundTrips int
+}
+
+func (ct *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ct.roundTrips++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithHTTPClient_UsesProvidedTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	httpClient := &http.Client{Transport: transport}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithHTTPClient(httpClient), WithTimeout(time.Second))
+	httpErr := client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	assertHttpError(t, httpErr, nil)
+	if transport.roundTrips != 1 {
+		t.Errorf("Expecting the custom transport to be used once, got=%d", transport.roundTrips)
+	}
+	if httpClient.Timeout != 0 {
+		t.Errorf("Expecting the provided client to be left alone, got timeout=%s", httpClient.Timeout)
+	}
+}
+
+func TestWithHTTPClient_Nil(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithHTTPClient(nil))
+	if err == nil || err.Error() != "http client must not be nil" {
+		t.Errorf("Expecting http client validation error, got=%v", err)
+	}
+}
+
+func TestWithProxy_RequestsGoThroughProxy(t *testing.T) {
+	proxied := ""
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a proxy gets the absolute url of the target in the request line
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, err := clientFactory.MakeClient("http://accounts.example", WithProxy(proxy.URL), WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("Expecting the client to be created, got=%v", err)
+	}
+	httpErr := client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	assertHttpError(t, httpErr, nil)
+	expected := "http://accounts.example/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2?version=0"
+	if proxied != expected {
+		t.Errorf("Expecting the proxy to get %s, got=%s", expected, proxied)
+	}
+}
+
+func TestWithProxy_InvalidURL(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithProxy("not a url"))
+	if err == nil || err.Error() != "invalid proxy URL provided" {
+		t.Errorf("Expecting proxy validation error, got=%v", err)
+	}
+}
+
+func TestWithProxy_LeavesProvidedTransportAlone(t *testing.T) {
+	transport := &http.Transport{}
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080",
+		WithHTTPClient(&http.Client{Transport: transport}), WithProxy("http://proxy.example:3128"))
+
+	if err != nil {
+		t.Fatalf("Expecting the client to be created, got=%v", err)
+	}
+	if transport.Proxy != nil {
+		t.Errorf("Expecting the provided transport not to be modified")
+	}
+
+	_, err = clientFactory.MakeClient("http://localhost:8080",
+		WithHTTPClient(&http.Client{Transport: &countingTransport{}}), WithProxy("http://proxy.example:3128"))
+	if err == nil || err.Error() != "transport must be an *http.Transport to be configured" {
+		t.Errorf("Expecting a transport type error, got=%v", err)
+	}
+}
+
+func TestWithMaxIdleConns(t *testing.T) {
+	provided := &http.Transport{}
+	clientFactory := AccountsHttpClientFactory{}
+	client, err := clientFactory.MakeClient("http://localhost:8080",
+		WithHTTPClient(&http.Client{Transport: provided}),
+		WithMaxIdleConns(200),
+		WithProxy("http://proxy.example:3128"),
+		WithMaxIdleConnsPerHost(50))
+
+	if err != nil {
+		t.Fatalf("Expecting the client to be created, got=%v", err)
+	}
+	transport := client.(*httpAccountsClientImpl).client.Transport.(*http.Transport)
+	if transport == provided || provided.MaxIdleConns != 0 || provided.MaxIdleConnsPerHost != 0 {
+		t.Errorf("Expecting the provided transport not to be modified")
+	}
+	if transport.MaxIdleConns != 200 || transport.MaxIdleConnsPerHost != 50 || transport.Proxy == nil {
+		t.Errorf("Expecting all settings on a single transport, got MaxIdleConns=%d MaxIdleConnsPerHost=%d and proxy set=%t",
+			transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.Proxy != nil)
+	}
+	if http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost != 0 {
+		t.Errorf("Expecting the default transport not to be modified")
+	}
+}
+
+func TestWithHTTPClient_KeepsEarlierOptions(t *testing.T) {
+	var chain []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chain = r.Header.Values("X-Chain")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	provided := &http.Transport{}
+	clientFactory := AccountsHttpClientFactory{}
+	client, err := clientFactory.MakeClient(server.URL,
+		WithTimeout(3*time.Second),
+		WithMaxIdleConns(200),
+		WithMaxIdleConnsPerHost(50),
+		WithRoundTripper(appendingHeader("outer")),
+		WithHTTPClient(&http.Client{Transport: provided}))
+
+	if err != nil {
+		t.Fatalf("Expecting the client to be created, got=%v", err)
+	}
+	httpClient := client.(*httpAccountsClientImpl).client
+	if httpClient.Timeout != 3*time.Second {
+		t.Errorf("Expecting the timeout to survive WithHTTPClient, got=%s", httpClient.Timeout)
+	}
+	owned := client.(*httpAccountsClientImpl).ownedTransport
+	if owned == nil || owned == provided || owned.MaxIdleConns != 200 || owned.MaxIdleConnsPerHost != 50 {
+		t.Errorf("Expecting the pool settings on a copy of the provided transport, got=%+v", owned)
+	}
+	client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+	if !assertPrimitiveSlices(chain, []string{"outer"}) {
+		t.Errorf("Expecting the round tripper to survive WithHTTPClient, got=%v", chain)
+	}
+}
+
+func TestWithHTTPClient_ProxyAfterForeignTransport(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080",
+		WithProxy("http://proxy.example:3128"), WithHTTPClient(&http.Client{Transport: &countingTransport{}}))
+
+	if err == nil || err.Error() != "transport must be an *http.Transport to be configured" {
+		t.Errorf("Expecting a transport type error whatever the order, got=%v", err)
+	}
+}
+
+func TestWithMaxIdleConns_NotPositive(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithMaxIdleConns(0))
+	if err == nil || err.Error() != "max idle conns must be positive" {
+		t.Errorf("Expecting max idle conns validation error, got=%v", err)
+	}
+	_, err = clientFactory.MakeClient("http://localhost:8080", WithMaxIdleConnsPerHost(-1))
+	if err == nil || err.Error() != "max idle conns per host must be positive" {
+		t.Errorf("Expecting max idle conns per host validation error, got=%v", err)
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	for _, userAgent := range []string{"onboarding-service/2.3", ""} {
+		client, _ := clientFactory.MakeClient(server.URL, WithUserAgent(userAgent))
+		client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+		client.Create(context.Background(), &AccountData{})
+		client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+	}
+
+	expected := []string{
+		"onboarding-service/2.3", "onboarding-service/2.3", "onboarding-service/2.3",
+		defaultUserAgent, defaultUserAgent, defaultUserAgent,
+	}
+	if !assertPrimitiveSlices(userAgents, expected) {
+		t.Errorf("Expecting user agents=%q, got=%q", expected, userAgents)
+	}
+}
+
+func TestWithAutoRequestID(t *testing.T) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Request-Id"))
+		w.Header().Set("X-Request-Id", r.Header.Get("X-Request-Id"))
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithAutoRequestID())
+	client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+	httpErr := client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	if len(ids) != 2 || !isValidUUID(ids[0]) || !isValidUUID(ids[1]) || ids[0] == ids[1] {
+		t.Fatalf("Expecting a distinct generated uuid for every request, got=%v", ids)
+	}
+	emptyPayload := make([]byte, 0)
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      409,
+		Status:          "409 Conflict",
+		Message:         "Unexpected response code returned for Delete operation, expected 204, got 409",
+		Kind:            KindConflict,
+		RequestID:       ids[1],
+		ResponsePayload: &emptyPayload,
+	})
+}
+
+func TestWithAutoRequestID_KeepsExplicitHeader(t *testing.T) {
+	id := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = r.Header.Get("X-Request-Id")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithAutoRequestID(), WithHeader("X-Request-Id", "batch-7"))
+	client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	if id != "batch-7" {
+		t.Errorf("Expecting the explicit request id, got=%s", id)
+	}
+}
+
+func TestWithMaxResponseBytes_BodyOverLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","type":"` + strings.Repeat("a", 1024) + `"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithMaxResponseBytes(512))
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:   errResponseTooLarge,
+		Message: "response body exceeded max size",
+		Kind:    KindNetwork,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestWithMaxResponseBytes_BodyAtLimit(t *testing.T) {
+	payload := `{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithMaxResponseBytes(int64(len(payload))))
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+}
+
+func TestWithMaxResponseBytes_GzippedBodyInflatingOverLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := gzipped(strings.NewReader(`{"data":{"type":"` + strings.Repeat("a", 4096) + `"}}`))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body.Bytes())
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithMaxResponseBytes(512))
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:   errResponseTooLarge,
+		Message: "response body exceeded max size",
+		Kind:    KindNetwork,
+	})
+}
+
+func TestWithMaxResponseBytes_NotPositive(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	for _, n := range []int64{0, -1} {
+		_, err := clientFactory.MakeClient("http://localhost:8080", WithMaxResponseBytes(n))
+		if err == nil || err.Error() != "max response bytes must be positive" {
+			t.Errorf("Expecting max response bytes=%d to be rejected, got=%v", n, err)
+		}
+	}
+}
+
+func TestWithSuccessStatus_CreateAccepted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expecting no polling, got=%s %s", r.Method, r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","type":"accounts"}}`))
+	}))
+	defer server.Close()
+
+	var asked []string
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithSuccessStatus(func(operation string, code int) bool {
+		asked = append(asked, fmt.Sprintf("%s %d", operation, code))
+		return operation == "Post" && code == http.StatusAccepted
+	}))
+	account, httpErr := client.Create(context.Background(), &AccountData{})
+
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2", Type: "accounts"})
+	if len(asked) == 0 || asked[0] != "Post 202" {
+		t.Errorf("Expecting the predicate to be asked about Post 202, got=%v", asked)
+	}
+}
+
+func TestWithSuccessStatus_OtherStatusesStillFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithSuccessStatus(func(operation string, code int) bool {
+		return operation == "Post" && code == http.StatusAccepted
+	}))
+	httpErr := client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	emptyPayload := make([]byte, 0)
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      202,
+		Status:          "202 Accepted",
+		Message:         "Unexpected response code returned for Delete operation, expected 204, got 202",
+		Kind:            KindServer,
+		ResponsePayload: &emptyPayload,
+	})
+}
+
+func TestWithSuccessStatus_Nil(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithSuccessStatus(nil))
+	if err == nil || err.Error() != "success status predicate must not be nil" {
+		t.Errorf("Expecting predicate validation error, got=%v", err)
+	}
+}
+
+func TestWithDefaultOrganisationID_Applied(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithDefaultOrganisationID("ba61483c-d5c5-4f50-ae81-6b8c039bea43"))
+	account := &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"}
+	created, httpErr := client.Create(context.Background(), account)
+
+	assertHttpError(t, httpErr, nil)
+	if received := server.lastAccount(http.MethodPost).OrganisationID; received != "ba61483c-d5c5-4f50-ae81-6b8c039bea43" {
+		t.Errorf("Expecting the default organisation id to be sent, got=%s", received)
+	}
+	if created.OrganisationID != "ba61483c-d5c5-4f50-ae81-6b8c039bea43" {
+		t.Errorf("Expecting the created account to carry the default organisation id, got=%s", created.OrganisationID)
+	}
+	if account.OrganisationID != "" {
+		t.Errorf("Expecting the caller's account to be left alone, got=%s", account.OrganisationID)
+	}
+}
+
+func TestWithDefaultOrganisationID_PerCallValueWins(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithDefaultOrganisationID("ba61483c-d5c5-4f50-ae81-6b8c039bea43"))
+	_, httpErr := client.Create(context.Background(), &AccountData{
+		ID:             "0d209d7f-d07a-4542-947f-5885fddddae2",
+		OrganisationID: "6fa0ab5a-5f1e-4c8b-9a0c-2c6e1d5b7f11",
+	})
+
+	assertHttpError(t, httpErr, nil)
+	if received := server.lastAccount(http.MethodPost).OrganisationID; received != "6fa0ab5a-5f1e-4c8b-9a0c-2c6e1d5b7f11" {
+		t.Errorf("Expecting the account's own organisation id to be sent, got=%s", received)
+	}
+}
+
+func TestWithDefaultOrganisationID_NotUuid(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithDefaultOrganisationID("acme"))
+
+	if err == nil || err.Error() != "default organisation id must be a valid uuid" {
+		t.Errorf("Expecting organisation id validation error, got=%v", err)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// appendingHeader is a middleware appending value to the X-Chain header of every request.
+func appendingHeader(value string) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Add("X-Chain", value)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func TestWithRoundTripper(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Values("X-Chain")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRoundTripper(appendingHeader("outer")))
+	httpErr := client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	assertHttpError(t, httpErr, nil)
+	if fmt.Sprint(received) != "[outer]" {
+		t.Errorf("Expecting the middleware's header to reach the server, got=%v", received)
+	}
+}
+
+func TestWithRoundTripper_ChainedInOrder(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Values("X-Chain")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL,
+		WithRoundTripper(appendingHeader("outer")),
+		WithRoundTripper(appendingHeader("inner")))
+	httpErr := client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	assertHttpError(t, httpErr, nil)
+	if fmt.Sprint(received) != "[outer inner]" {
+		t.Errorf("Expecting the first middleware to see the request first, got=%v", received)
+	}
+}
+
+func TestWithRoundTripper_Nil(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithRoundTripper(nil))
+
+	if err == nil || err.Error() != "round tripper wrapper must not be nil" {
+		t.Errorf("Expecting round tripper validation error, got=%v", err)
+	}
+}
+
+func TestCreate_EmptyFieldsOmitted(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	_, httpErr := client.Create(context.Background(), &AccountData{
+		ID:         "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Attributes: &AccountAttributes{CustomerId: "", BankID: "400300"},
+	})
+
+	assertHttpError(t, httpErr, nil)
+	expected := `{"data":{"attributes":{"bank_id":"400300"},"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`
+	if received := server.lastBody(http.MethodPost); received != expected {
+		t.Errorf("Expecting payload=%s, got=%s", expected, received)
+	}
+}
+
+func TestWithMarshalIndent(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithMarshalIndent())
+	_, httpErr := client.Create(context.Background(), &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+
+	assertHttpError(t, httpErr, nil)
+	expected := "{\n  \"data\": {\n    \"id\": \"0d209d7f-d07a-4542-947f-5885fddddae2\"\n  }\n}"
+	if received := server.lastBody(http.MethodPost); received != expected {
+		t.Errorf("Expecting payload=%s, got=%s", expected, received)
+	}
+}
+
+func TestWithSerializer(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	serialized := 0
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithSerializer(func(v any) ([]byte, error) {
+		serialized++
+		return json.Marshal(v)
+	}))
+	_, httpErr := client.Create(context.Background(), &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+
+	assertHttpError(t, httpErr, nil)
+	if serialized != 1 {
+		t.Errorf("Expecting the payload to go through the serializer, got %d calls", serialized)
+	}
+}
+
+func TestWithSerializer_Nil(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithSerializer(nil))
+
+	if err == nil || err.Error() != "serializer must not be nil" {
+		t.Errorf("Expecting serializer validation error, got=%v", err)
+	}
+}
+
+func TestWithResponseInterceptor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "abc")
+		if strings.HasSuffix(r.URL.Path, "/0d209d7f-d07a-4542-947f-5885fddddae2") {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error_message":"invalid"}`))
+	}))
+	defer server.Close()
+
+	var statusCodes []int
+	var requestIDs []string
+	intercept := func(resp *http.Response) {
+		statusCodes = append(statusCodes, resp.StatusCode)
+		requestIDs = append(requestIDs, resp.Header.Get("X-Request-Id"))
+		// whatever the interceptor does with the body, the client still gets to read it
+		io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithResponseInterceptor(intercept))
+
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	assertHttpError(t, httpErr, nil)
+	if account == nil || account.ID != "0d209d7f-d07a-4542-947f-5885fddddae2" {
+		t.Errorf("Expecting the account to be read after interception, got=%v", account)
+	}
+	_, httpErr = client.Fetch(context.Background(), "14fc6e43-4cd0-49ee-a6b0-cf5a3d8d1d5f")
+	if httpErr == nil || httpErr.StatusCode != http.StatusBadRequest || httpErr.ServerMessage != "invalid" {
+		t.Errorf("Expecting the 400 to be reported with its payload, got=%v", httpErr)
+	}
+
+	if !assertPrimitiveSlices(statusCodes, []int{http.StatusOK, http.StatusBadRequest}) {
+		t.Errorf("Expecting the interceptor to see both status codes, got=%v", statusCodes)
+	}
+	if !assertPrimitiveSlices(requestIDs, []string{"abc", "abc"}) {
+		t.Errorf("Expecting the interceptor to see the headers, got=%v", requestIDs)
+	}
+}
+
+func TestWithResponseInterceptor_SeesEveryAttempt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	intercepted := 0
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(3, 0), WithResponseInterceptor(func(*http.Response) {
+		intercepted++
+	}))
+	client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	if intercepted != 3 {
+		t.Errorf("Expecting every attempt to be intercepted, got %d", intercepted)
+	}
+}
+
+func TestWithResponseInterceptor_Nil(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithResponseInterceptor(nil))
+
+	if err == nil || err.Error() != "response interceptor must not be nil" {
+		t.Errorf("Expecting response interceptor validation error, got=%v", err)
+	}
+}
+
+func TestWithFetchNormalizer(t *testing.T) {
+	account := `{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","attributes":{"bic":"nwbkgb22"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Path == "/v1/organisation/accounts" {
+			w.Write([]byte(`{"data":[` + account + `]}`))
+			return
+		}
+		w.Write([]byte(`{"data":` + account + `}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithFetchNormalizer(func(a *AccountData) {
+		a.Attributes.Bic = strings.ToUpper(a.Attributes.Bic)
+	}))
+
+	fetched, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	assertHttpError(t, httpErr, nil)
+	if fetched.Attributes.Bic != "NWBKGB22" {
+		t.Errorf("Expecting the fetched account to be normalized, got bic=%s", fetched.Attributes.Bic)
+	}
+	listed, _, httpErr := client.List(context.Background(), 0, 10)
+	assertHttpError(t, httpErr, nil)
+	if len(listed) != 1 || listed[0].Attributes.Bic != "NWBKGB22" {
+		t.Errorf("Expecting the listed account to be normalized, got=%v", listed)
+	}
+}
+
+func TestWithFetchNormalizer_NotCalledOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	calls := 0
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithFetchNormalizer(func(*AccountData) { calls++ }))
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	if !httpErr.IsNotFound() || calls != 0 {
+		t.Errorf("Expecting a 404 without normalizing, got %d calls and error=%v", calls, httpErr)
+	}
+}
+
+func TestWithFetchNormalizer_Nil(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithFetchNormalizer(nil))
+
+	if err == nil || err.Error() != "fetch normalizer must not be nil" {
+		t.Errorf("Expecting normalizer validation error, got=%v", err)
+	}
+}
+
+func TestWithAutoPaginate(t *testing.T) {
+	pages := map[string]string{
+		"0": `{"data":[{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}],
+			"links":{"next":"/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=100"}}`,
+		"1": `{"data":[{"id":"6b7e2a4c-8e0f-4b1d-9d3a-5f2c7e1a9b40"}],"links":{"next":""}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(pages[r.URL.Query().Get("page[number]")]))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithAutoPaginate())
+
+	single, links, httpErr := client.List(context.Background(), 0, 100)
+	assertHttpError(t, httpErr, nil)
+	if len(single) != 1 || !links.HasNext() {
+		t.Errorf("Expecting a single page with a next link for explicit page parameters, got %d accounts", len(single))
+	}
+
+	all, links, httpErr := client.List(context.Background(), 0, 0)
+	assertHttpError(t, httpErr, nil)
+	if len(all) != 2 || all[0].ID != "0d209d7f-d07a-4542-947f-5885fddddae2" || all[1].ID != "6b7e2a4c-8e0f-4b1d-9d3a-5f2c7e1a9b40" {
+		t.Errorf("Expecting the accounts of both pages, got %d accounts", len(all))
+	}
+	if links != nil {
+		t.Errorf("Expecting no links, got=%v", links)
+	}
+
+	client, _ = clientFactory.MakeClient(server.URL)
+	_, _, httpErr = client.List(context.Background(), 0, 0)
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "page size must be between 1 and 100",
+		Kind:    KindValidation,
+	})
+}
+
+func TestWithAutoPaginate_GivesUpAfterMaxPages(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		// every page links to a page never seen before
+		fmt.Fprintf(w, `{"data":[],"links":{"next":"/v1/organisation/accounts?page%%5Bnumber%%5D=%d&page%%5Bsize%%5D=100"}}`, requests)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithAutoPaginate())
+	accounts, _, httpErr := client.List(context.Background(), 0, 0)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "gave up paginating after 1000 pages",
+		Kind:    KindServer,
+	})
+	if accounts != nil || requests != 1000 {
+		t.Errorf("Expecting no accounts after 1000 requests, got %d requests", requests)
+	}
+}
+
+func TestWithDeleteVerification_AccountStillExists(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithDeleteVerification())
+	httpErr := client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "account still exists after delete",
+		Kind:    KindServer,
+	})
+	if server.count(http.MethodGet) != 1 {
+		t.Errorf("Expecting a single fetch after the delete, got=%d", server.count(http.MethodGet))
+	}
+}
+
+func TestWithDeleteVerification_AccountGone(t *testing.T) {
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		fetches++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithDeleteVerification())
+	httpErr := client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	assertHttpError(t, httpErr, nil)
+	if fetches != 1 {
+		t.Errorf("Expecting a single fetch after the delete, got=%d", fetches)
+	}
+
+	client, _ = clientFactory.MakeClient(server.URL)
+	client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+	if fetches != 1 {
+		t.Errorf("Expecting no fetch without verification, got=%d", fetches)
+	}
+}
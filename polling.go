@@ -0,0 +1,126 @@
+package interview_accountapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const location = "Location"
+const expectedLatency = "X-Expected-Latency"
+const defaultPollInterval = time.Second
+const maxPollAttempts = 30
+
+// awaitCompletion follows up on a 202 Accepted response by polling the url from its Location header
+// until the server reports the resource as available (status code 200 or 201).
+// The server may advertise how long processing is expected to take via the X-Expected-Latency header,
+// either as a duration (e.g. "250ms") or as a number of seconds; it is honored before every poll
+// and defaults to a second otherwise.
+// Polling stops with an HTTPError once the context is done, after maxPollAttempts polls,
+// or when the server responds with any other status code.
+func (hac *httpAccountsClientImpl) awaitCompletion(ctx context.Context, accepted *http.Response) (*AccountData, *HTTPError) {
+	statusUrl := accepted.Header.Get(location)
+	if statusUrl == "" {
+		return nil, &HTTPError{
+			StatusCode: accepted.StatusCode,
+			Status:     accepted.Status,
+			Message:    msgNoLocation,
+			Kind:       KindServer,
+		}
+	}
+
+	path, err := hac.resolveLink(statusUrl)
+	if err != nil {
+		return nil, &HTTPError{
+			Cause:      err,
+			StatusCode: accepted.StatusCode,
+			Status:     accepted.Status,
+			Message:    msgParsingLocation,
+			Kind:       KindServer,
+		}
+	}
+
+	delay := pollInterval(accepted)
+	for attempt := 0; attempt < maxPollAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, &HTTPError{
+				Cause:   ctx.Err(),
+				Message: msgGaveUpPolling,
+				Kind:    KindNetwork,
+			}
+		case <-hac.clock.After(delay):
+		}
+
+		account, resp, httpErr := hac.poll(ctx, path)
+		if httpErr != nil || account != nil {
+			return account, httpErr
+		}
+		delay = pollInterval(resp)
+	}
+
+	return nil, &HTTPError{
+		StatusCode: http.StatusAccepted,
+		Status:     fmt.Sprintf("%d %s", http.StatusAccepted, http.StatusText(http.StatusAccepted)),
+		Message:    fmt.Sprintf(msgPollsExhausted, maxPollAttempts),
+		Kind:       KindServer,
+	}
+}
+
+// poll returns the account once it is available, or the response when the operation is still in progress.
+func (hac *httpAccountsClientImpl) poll(ctx context.Context, path string) (*AccountData, *http.Response, *HTTPError) {
+	resp, err := hac.doHttpGet(ctx, path)
+	if err != nil {
+		return nil, nil, placingError(err, "Get")
+	}
+
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	responseData, httpErr := hac.readPayload(resp)
+	if httpErr != nil {
+		return nil, nil, httpErr
+	}
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return nil, resp, nil
+	case http.StatusOK, http.StatusCreated:
+	default:
+		return nil, nil,
+			hac.unexpectedStatusCode(http.StatusOK, resp, "Poll", responseData)
+	}
+
+	if httpErr := expectJsonContentType(resp, responseData); httpErr != nil {
+		return nil, nil, httpErr
+	}
+
+	if httpErr := hac.expectSchemaVersion(resp, responseData); httpErr != nil {
+		return nil, nil, httpErr
+	}
+
+	responseEnvelope, httpErr := hac.deserializeToResponseEnvelope(responseData)
+	if httpErr != nil {
+		return nil, nil, httpErr
+	}
+
+	account, httpErr := accountDataOrError(responseEnvelope, responseData)
+	return account, nil, httpErr
+}
+
+func pollInterval(resp *http.Response) time.Duration {
+	advertised := resp.Header.Get(expectedLatency)
+	if advertised == "" {
+		return defaultPollInterval
+	}
+	if d, err := time.ParseDuration(advertised); err == nil && d >= 0 {
+		return d
+	}
+	if seconds, err := strconv.Atoi(advertised); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultPollInterval
+}
@@ -0,0 +1,117 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreate_AcceptedThenCompleted(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.Header().Set("Location", "/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2/status")
+			w.Header().Set("X-Expected-Latency", "10ms")
+			w.WriteHeader(http.StatusAccepted)
+		case http.MethodGet:
+			if r.URL.Path != "/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2/status" {
+				t.Errorf("unexpected polling path, got=%s", r.URL.Path)
+			}
+			polls++
+			if polls < 3 {
+				w.Header().Set("X-Expected-Latency", "5ms")
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","type":"accounts"}}`))
+		}
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.Create(context.Background(), &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2", Type: "accounts"})
+	if polls != 3 {
+		t.Errorf("Expecting 3 polls, got=%d", polls)
+	}
+}
+
+func TestCreate_AcceptedWithoutLocation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.Create(context.Background(), &AccountData{})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode: 202,
+		Status:     "202 Accepted",
+		Message:    "accepted response carries no Location to poll",
+		Kind:       KindServer,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestCreate_AcceptedPollingFails(t *testing.T) {
+	payload := []byte("boom")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Header().Set("Location", "/status")
+			w.Header().Set("X-Expected-Latency", "0")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.Create(context.Background(), &AccountData{})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      500,
+		Status:          "500 Internal Server Error",
+		Message:         "Unexpected response code returned for Poll operation, expected 200, got 500",
+		Kind:            KindServer,
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestCreate_AcceptedPollingTimesOut(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/status")
+		w.Header().Set("X-Expected-Latency", "1")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.Create(ctx, &AccountData{})
+
+	if httpErr == nil || !errors.Is(httpErr.Cause, context.DeadlineExceeded) {
+		t.Fatalf("Expecting http error caused by context.DeadlineExceeded, got=%v", httpErr)
+	}
+	if httpErr.Message != "gave up waiting for accepted operation to complete" {
+		t.Errorf("HttpError message doesn't match, got=%s", httpErr.Message)
+	}
+	assertAccountData(t, account, nil)
+}
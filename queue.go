@@ -0,0 +1,91 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// errQueueFull tells that a request was never sent because the request queue had no room left for it.
+var errQueueFull = errors.New("request queue is full")
+
+// requestQueue hands out the times at which queued requests go, interval apart and in the order they arrived.
+type requestQueue struct {
+	mu       sync.Mutex
+	interval time.Duration
+	maxQueue int
+	next     time.Time
+	waiting  int
+}
+
+// WithRequestQueue smooths bursts of outgoing requests, retries and polling included, out to rate requests per second.
+// Requests line up in a FIFO queue and go in the order they arrived, a request arriving while maxQueue requests
+// are already waiting fails right away instead of joining the queue. Unlike WithRateLimit, which lets bursts through
+// and has the requests over the limit wait for as long as it takes, the queue never sends two requests closer together
+// than 1/rate seconds and bounds how many requests may be held up.
+// A single queue is shared by all the operations of the client, including concurrent ones.
+func WithRequestQueue(rate float64, maxQueue int) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if rate <= 0 {
+			return errors.New("queue drain rate must be positive")
+		}
+		if maxQueue < 1 {
+			return errors.New("max queue length must be positive")
+		}
+		hac.queue = &requestQueue{interval: time.Duration(float64(time.Second) / rate), maxQueue: maxQueue}
+		return nil
+	}
+}
+
+// queued wraps the request invoker so that every request waits for its turn in the request queue first.
+// The wait is timed by the client's clock, see WithClock.
+func (hac *httpAccountsClientImpl) queued(doRequest DoRequest) DoRequest {
+	return func(req *http.Request) (*http.Response, error) {
+		if err := hac.queue.wait(req.Context(), hac.clock); err != nil {
+			return nil, err
+		}
+		return doRequest(req)
+	}
+}
+
+// wait takes the next free slot of the queue and waits until it is due, failing with errQueueFull
+// when the slot would have to be waited for and maxQueue requests are waiting already.
+// A request whose context ends while waiting leaves its slot unused, the ones behind it keep theirs.
+func (q *requestQueue) wait(ctx context.Context, clock Clock) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := clock.Now()
+	q.mu.Lock()
+	slot := q.next
+	if slot.Before(now) {
+		slot = now
+	}
+	delay := slot.Sub(now)
+	if delay > 0 && q.waiting >= q.maxQueue {
+		q.mu.Unlock()
+		return errQueueFull
+	}
+	q.next = slot.Add(q.interval)
+	if delay > 0 {
+		q.waiting++
+	}
+	q.mu.Unlock()
+	if delay == 0 {
+		return nil
+	}
+
+	defer func() {
+		q.mu.Lock()
+		q.waiting--
+		q.mu.Unlock()
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clock.After(delay):
+		return nil
+	}
+}
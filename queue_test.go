@@ -0,0 +1,92 @@
+package interview_accountapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithRequestQueue_InvalidArguments(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithRequestQueue(0, 1))
+	if err == nil || err.Error() != "queue drain rate must be positive" {
+		t.Errorf("Expecting drain rate validation error, got=%v", err)
+	}
+
+	_, err = clientFactory.MakeClient("http://localhost:8080", WithRequestQueue(1, 0))
+	if err == nil || err.Error() != "max queue length must be positive" {
+		t.Errorf("Expecting max queue length validation error, got=%v", err)
+	}
+}
+
+func TestWithRequestQueue_PreservesOrderAndFailsFastOnOverflow(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRequestQueue(5, 2))
+	queue := client.(*httpAccountsClientImpl).queue
+	ids := []string{
+		"0d209d7f-d07a-4542-947f-5885fddddae2",
+		"1d209d7f-d07a-4542-947f-5885fddddae2",
+		"2d209d7f-d07a-4542-947f-5885fddddae2",
+	}
+
+	// each request joins the queue before the next one is made, the first goes right away and the others wait
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			assertHttpError(t, client.Delete(context.Background(), id, 0), nil)
+		}(id)
+		waitForQueue(t, queue, i)
+	}
+
+	start := time.Now()
+	httpErr := client.Delete(context.Background(), "3d209d7f-d07a-4542-947f-5885fddddae2", 0)
+	if httpErr == nil || httpErr.Message != "request queue is full, request not sent" || httpErr.Kind != KindNetwork {
+		t.Errorf("Expecting the request to be turned away by the full queue, got=%v", httpErr)
+	}
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Errorf("Expecting the request to fail fast, took=%s", elapsed)
+	}
+	wg.Wait()
+
+	if len(paths) != len(ids) {
+		t.Fatalf("Expecting %d requests to reach the server, got=%v", len(ids), paths)
+	}
+	for i, id := range ids {
+		if expected := "/v1/organisation/accounts/" + id; paths[i] != expected {
+			t.Errorf("Expecting request %d to be %s, got=%s", i, expected, paths[i])
+		}
+	}
+}
+
+// waitForQueue waits until queue has handed out a slot and waiting requests are waiting for theirs,
+// failing the test after a second.
+func waitForQueue(t *testing.T, queue *requestQueue, waiting int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		queue.mu.Lock()
+		done := !queue.next.IsZero() && queue.waiting == waiting
+		queue.mu.Unlock()
+		if done {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Expecting %d requests to be waiting in the queue within a second", waiting)
+}
@@ -0,0 +1,74 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/time/rate"
+	"net/http"
+)
+
+// rateLimitWaitError tells that a request was never sent because the context ended while waiting for the rate limiter.
+type rateLimitWaitError struct {
+	cause error
+}
+
+func (e *rateLimitWaitError) Error() string {
+	return "rate limiter wait cancelled: " + e.cause.Error()
+}
+
+func (e *rateLimitWaitError) Unwrap() error {
+	return e.cause
+}
+
+// WithRateLimit throttles outgoing requests, retries and polling included, to rps requests per second
+// with bursts of up to burst requests. Requests over the limit wait for their turn, for as long as their context allows.
+// A single limiter is shared by all the operations of the client, including concurrent ones.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if rps <= 0 {
+			return errors.New("rate limit must be positive")
+		}
+		if burst < 1 {
+			return errors.New("burst must be positive")
+		}
+		hac.limiter = rate.NewLimiter(rate.Limit(rps), burst)
+		return nil
+	}
+}
+
+// limited wraps the request invoker so that every request waits for the rate limiter first.
+// The wait is timed by the client's clock, see WithClock.
+func (hac *httpAccountsClientImpl) limited(doRequest DoRequest) DoRequest {
+	return func(req *http.Request) (*http.Response, error) {
+		if err := hac.waitForToken(req.Context()); err != nil {
+			return nil, &rateLimitWaitError{cause: err}
+		}
+		return doRequest(req)
+	}
+}
+
+// waitForToken reserves a token of the limiter and waits until it is due,
+// handing the token back when the context ends before that.
+func (hac *httpAccountsClientImpl) waitForToken(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := hac.clock.Now()
+	reservation := hac.limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		return nil
+	}
+	// no point in waiting when it can already be told the wait would outlast the deadline
+	if deadline, ok := ctx.Deadline(); ok && deadline.Sub(now) < delay {
+		reservation.CancelAt(now)
+		return context.DeadlineExceeded
+	}
+	select {
+	case <-ctx.Done():
+		reservation.CancelAt(hac.clock.Now())
+		return ctx.Err()
+	case <-hac.clock.After(delay):
+		return nil
+	}
+}
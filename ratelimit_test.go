@@ -0,0 +1,94 @@
+package interview_accountapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit_InvalidArguments(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithRateLimit(0, 1))
+	if err == nil || err.Error() != "rate limit must be positive" {
+		t.Errorf("Expecting rate limit validation error, got=%v", err)
+	}
+
+	_, err = clientFactory.MakeClient("http://localhost:8080", WithRateLimit(1, 0))
+	if err == nil || err.Error() != "burst must be positive" {
+		t.Errorf("Expecting burst validation error, got=%v", err)
+	}
+}
+
+func TestWithRateLimit_ThrottlesRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRateLimit(20, 1))
+
+	requests := 5
+	start := time.Now()
+	for i := 0; i < requests; i++ {
+		httpErr := client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+		assertHttpError(t, httpErr, nil)
+	}
+	elapsed := time.Since(start)
+
+	// the first request is covered by the burst, each of the others waits for a token
+	floor := time.Duration(requests-1) * time.Second / 20
+	if elapsed < floor {
+		t.Errorf("Expecting %d requests to take at least %s, took=%s", requests, floor, elapsed)
+	}
+}
+
+func TestWithRateLimit_WaitCancelled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRateLimit(0.1, 1))
+	client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	httpErr := client.Delete(ctx, "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:   context.DeadlineExceeded,
+		Message: "rate limiter wait cancelled",
+		Kind:    KindNetwork,
+	})
+	if requests != 1 {
+		t.Errorf("Expecting the throttled request not to be sent, got %d requests", requests)
+	}
+}
+
+func TestWithRateLimit_WaitsOnClock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRateLimit(1, 1), WithClock(clock))
+	for i := 0; i < 3; i++ {
+		httpErr := client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+		assertHttpError(t, httpErr, nil)
+	}
+
+	// the first request is covered by the burst, each of the others waits a second for its token
+	delays := clock.Delays()
+	if len(delays) != 2 || delays[0] != time.Second || delays[1] != time.Second {
+		t.Errorf("Expecting two waits of a second, got=%v", delays)
+	}
+}
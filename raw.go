@@ -0,0 +1,71 @@
+package interview_accountapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// idempotentMethods are the methods DoRaw retries, a request with any other method may have had side effects
+// on an endpoint the client knows nothing about, e.g. whether it honours an Idempotency-Key, and is sent only once.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+func (hac *httpAccountsClientImpl) DoRaw(ctx context.Context, method, subPath string, body []byte, headers http.Header) (_ *http.Response, _ []byte, e *HTTPError) {
+	ctx, endSpan := hac.startSpan(ctx, "DoRaw")
+	defer func() { endSpan(e) }()
+
+	path := hac.host + "/" + strings.TrimLeft(subPath, "/")
+	send := func() (*http.Response, error) {
+		var reader io.Reader
+		if body != nil {
+			// every attempt needs a fresh reader, the previous one has already been consumed
+			reader = bytes.NewReader(body)
+		}
+		req, err := hac.newRequest(ctx, method, path, reader)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range headers {
+			req.Header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+		}
+		return hac.doRequest(req)
+	}
+	var resp *http.Response
+	var outcome retryOutcome
+	var err error
+	if idempotentMethods[method] {
+		resp, outcome, err = hac.sendWithRetry(ctx, true, send)
+	} else {
+		resp, err = send()
+		outcome = retryOutcome{attempts: 1}
+	}
+
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		// methods are named as in the errors of the typed operations, e.g. "Get"
+		name := method
+		if name != "" {
+			name = name[:1] + strings.ToLower(name[1:])
+		}
+		return nil, nil, outcome.annotate(placingError(err, name))
+	}
+
+	responseData, httpErr := hac.readPayload(resp)
+	if httpErr != nil {
+		return nil, nil, httpErr
+	}
+	// the body has been read and gets closed here, the caller is handed a copy it is free to read or not
+	resp.Body = io.NopCloser(bytes.NewReader(*responseData))
+	return resp, *responseData, nil
+}
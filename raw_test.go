@@ -0,0 +1,124 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDoRaw(t *testing.T) {
+	var method, path, auth, custom, received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.RequestURI()
+		auth, custom = r.Header.Get("Authorization"), r.Header.Get("X-Custom")
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(`{"data":"raw"}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithAuthToken("secret"))
+	resp, body, httpErr := client.DoRaw(context.Background(), http.MethodPut, "/v1/organisation/parties?filter=x",
+		[]byte(`{"data":{}}`), http.Header{"X-Custom": []string{"yes"}})
+
+	assertHttpError(t, httpErr, nil)
+	if method != http.MethodPut || path != "/v1/organisation/parties?filter=x" {
+		t.Errorf("Expecting PUT /v1/organisation/parties?filter=x, got=%s %s", method, path)
+	}
+	if auth != "Bearer secret" || custom != "yes" {
+		t.Errorf("Expecting both the configured and the given headers, got Authorization=%s, X-Custom=%s", auth, custom)
+	}
+	if received != `{"data":{}}` {
+		t.Errorf("Expecting the body to be sent, got=%s", received)
+	}
+	if resp.StatusCode != http.StatusTeapot || string(body) != `{"data":"raw"}` {
+		t.Errorf("Expecting the raw response, got=%d %s", resp.StatusCode, body)
+	}
+	if copied, _ := io.ReadAll(resp.Body); string(copied) != `{"data":"raw"}` {
+		t.Errorf("Expecting the response body to remain readable, got=%s", copied)
+	}
+}
+
+func TestDoRaw_Head(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the length of the body a GET would get, which a HEAD response declares without carrying it
+		w.Header().Set("Content-Length", "14")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	resp, body, httpErr := client.DoRaw(context.Background(), http.MethodHead, "/v1/health", nil, nil)
+
+	assertHttpError(t, httpErr, nil)
+	if resp.StatusCode != http.StatusOK || resp.ContentLength != 14 || len(body) != 0 {
+		t.Errorf("Expecting a bodiless 200 declaring a length of 14, got=%d %d %s", resp.StatusCode, resp.ContentLength, body)
+	}
+}
+
+func TestDoRaw_Retried(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(2, 0))
+	resp, _, httpErr := client.DoRaw(context.Background(), http.MethodGet, "health", nil, nil)
+
+	assertHttpError(t, httpErr, nil)
+	if resp.StatusCode != http.StatusOK || attempts != 2 {
+		t.Errorf("Expecting a retried 200, got=%d after %d attempts", resp.StatusCode, attempts)
+	}
+}
+
+func TestDoRaw_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request with a cancelled context must not reach the server")
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	resp, body, httpErr := client.DoRaw(ctx, http.MethodGet, "/v1/organisation/parties", nil, nil)
+
+	if httpErr == nil || !errors.Is(httpErr.Cause, context.Canceled) || httpErr.Message != "Error placing Get Http request" {
+		t.Errorf("Expecting http error caused by context.Canceled, got=%v", httpErr)
+	}
+	if resp != nil || body != nil {
+		t.Errorf("Expecting no response")
+	}
+}
+
+func TestDoRaw_PostNotRetried(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(3, 0))
+	resp, _, httpErr := client.DoRaw(context.Background(), http.MethodPost, "/v1/organisation/parties", []byte(`{"data":{}}`), nil)
+
+	assertHttpError(t, httpErr, nil)
+	if resp.StatusCode != http.StatusServiceUnavailable || attempts != 1 {
+		t.Errorf("Expecting a single 503, got=%d after %d attempts", resp.StatusCode, attempts)
+	}
+}
@@ -0,0 +1,76 @@
+package interview_accountapi
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// WithBaseURLResolver makes the client resolve the base url of every request with resolve, e.g. from a service
+// registry, instead of using the one the client was built with, which merely stands in for it.
+// The resolved url is validated on every request, a failure to resolve one or an invalid one is reported as
+// an HTTPError of kind KindNetwork. resolve gets called concurrently when the client is, so it must be safe for it.
+func WithBaseURLResolver(resolve func() (string, error)) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if resolve == nil {
+			return errors.New("base url resolver must not be nil")
+		}
+		hac.resolveBaseURL = resolve
+		return nil
+	}
+}
+
+// baseURLError is a failure to resolve the base url of a request, see WithBaseURLResolver.
+type baseURLError struct {
+	cause error
+}
+
+func (e *baseURLError) Error() string {
+	return msgResolvingBaseURL + ": " + e.cause.Error()
+}
+
+func (e *baseURLError) Unwrap() error {
+	return e.cause
+}
+
+// resolvedPath swaps the base url the client was built with for the one currently resolved, if there is a resolver.
+// Paths not starting with the base url, e.g. links to another host handed out by the server, are left as they are.
+func (hac *httpAccountsClientImpl) resolvedPath(path string) (string, error) {
+	if hac.resolveBaseURL == nil || !hasBaseURL(path, hac.host) {
+		return path, nil
+	}
+	base, err := hac.resolveBaseURL()
+	if err != nil {
+		return "", &baseURLError{cause: err}
+	}
+	if err := validateUrl(base); err != nil {
+		return "", &baseURLError{cause: err}
+	}
+	if parsed, _ := url.Parse(base); parsed.Host == "" {
+		return "", &baseURLError{cause: errors.New("base url has no host: " + base)}
+	}
+	return strings.TrimRight(base, "/") + path[len(hac.host):], nil
+}
+
+// hasBaseURL tells whether path is made of base, i.e. whether it starts with it followed by nothing but a path
+// or a query, so that a host merely starting like the one of base doesn't count.
+func hasBaseURL(path, base string) bool {
+	if !strings.HasPrefix(path, base) {
+		return false
+	}
+	rest := path[len(base):]
+	return rest == "" || rest[0] == '/' || rest[0] == '?'
+}
+
+// baseURLFailure reports err as a failure to resolve the base url if it is one, nil otherwise.
+func baseURLFailure(err error) *HTTPError {
+	var resolveErr *baseURLError
+	if !errors.As(err, &resolveErr) {
+		return nil
+	}
+	return &HTTPError{
+		Cause:   resolveErr.cause,
+		Message: msgResolvingBaseURL,
+		Kind:    KindNetwork,
+	}
+}
@@ -0,0 +1,101 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithBaseURLResolver(t *testing.T) {
+	hits := map[string]int{}
+	makeServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits[name]++
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+		}))
+	}
+	first, second := makeServer("first"), makeServer("second")
+	defer first.Close()
+	defer second.Close()
+
+	hosts := []string{first.URL, second.URL + "/", first.URL}
+	calls := 0
+	resolve := func() (string, error) {
+		host := hosts[calls%len(hosts)]
+		calls++
+		return host, nil
+	}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("http://localhost:1", WithBaseURLResolver(resolve))
+
+	for i := 0; i < 3; i++ {
+		_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+		assertHttpError(t, httpErr, nil)
+	}
+	if calls != 3 || hits["first"] != 2 || hits["second"] != 1 {
+		t.Errorf("Expecting each request to go to the host resolved for it, got %d resolutions and hits=%v", calls, hits)
+	}
+}
+
+func TestWithBaseURLResolver_Failure(t *testing.T) {
+	registryDown := errors.New("registry down")
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("http://localhost:1", WithBaseURLResolver(func() (string, error) {
+		return "", registryDown
+	}))
+	expected := &HTTPError{
+		Cause:   registryDown,
+		Message: "failed to resolve base URL",
+		Kind:    KindNetwork,
+	}
+
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	assertHttpError(t, httpErr, expected)
+	_, httpErr = client.Update(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0, &AccountData{})
+	assertHttpError(t, httpErr, expected)
+	assertHttpError(t, client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0), expected)
+}
+
+func TestWithBaseURLResolver_InvalidURL(t *testing.T) {
+	for _, resolved := range []string{"not a url", "/v1"} {
+		t.Run(resolved, func(t *testing.T) {
+			clientFactory := AccountsHttpClientFactory{}
+			client, _ := clientFactory.MakeClient("http://localhost:1", WithBaseURLResolver(func() (string, error) {
+				return resolved, nil
+			}))
+			_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+			if httpErr == nil || httpErr.Message != "failed to resolve base URL" || !httpErr.IsNetwork() || httpErr.Cause == nil {
+				t.Errorf("Expecting a failure to resolve the base URL, got=%v", httpErr)
+			}
+		})
+	}
+}
+
+func TestWithBaseURLResolver_Nil(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:1", WithBaseURLResolver(nil))
+
+	if err == nil || err.Error() != "base url resolver must not be nil" {
+		t.Errorf("Expecting the nil resolver to be rejected, got=%v", err)
+	}
+}
+
+func TestHasBaseURL(t *testing.T) {
+	tests := map[string]bool{
+		"http://host":                          true,
+		"http://host/v1/organisation/accounts": true,
+		"http://host?page=1":                   true,
+		"http://hostile/v1":                    false,
+		"http://other/v1":                      false,
+	}
+	for path, expected := range tests {
+		if actual := hasBaseURL(path, "http://host"); actual != expected {
+			t.Errorf("Expecting hasBaseURL(%s)=%t, got=%t", path, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package interview_accountapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+}
+
+// WithRetry makes Fetch, Create and List retry transient failures, i.e. connection errors
+// and 500, 502, 503 and 504 responses, up to maxAttempts attempts in total.
+// Attempts are spaced out using exponential backoff starting at baseDelay, with jitter applied
+// so that many clients failing at once don't retry in lockstep.
+// 429 responses are retried as well, after the delay given in their Retry-After header if any.
+// Other client errors (4xx) are never retried, and retrying stops as soon as the context is done.
+// When more than one attempt was made, the returned HTTPError message says how many.
+// Create requests are sent with a generated Idempotency-Key header, the same for every attempt,
+// so that a create that reached the server before failing doesn't end up as a duplicate.
+func WithRetry(maxAttempts int, baseDelay time.Duration) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if maxAttempts < 1 {
+			return errors.New("max attempts must be positive")
+		}
+		if baseDelay < 0 {
+			return errors.New("base delay must not be negative")
+		}
+		hac.retry = &retryPolicy{
+			maxAttempts: maxAttempts,
+			baseDelay:   baseDelay,
+		}
+		return nil
+	}
+}
+
+// WithRetryMaxDelay caps the backoff between two attempts at d, however many attempts were made so far.
+// The cap doesn't apply to the delay a 429 response asks for in its Retry-After header.
+// It only has an effect together with WithRetry.
+func WithRetryMaxDelay(d time.Duration) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if d <= 0 {
+			return errors.New("max retry delay must be positive")
+		}
+		hac.retryMaxDelay = d
+		return nil
+	}
+}
+
+// WithRetryMaxElapsed bounds the time spent retrying an operation: no further attempt is made once waiting for it
+// would take the time elapsed since the first attempt beyond d. The last outcome is then returned, its HTTPError
+// telling how many attempts were made and how long it took. It only has an effect together with WithRetry.
+func WithRetryMaxElapsed(d time.Duration) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if d <= 0 {
+			return errors.New("max retry elapsed time must be positive")
+		}
+		hac.retryMaxElapsed = d
+		return nil
+	}
+}
+
+// WithRetryOnDeserializeError additionally retries successful Fetch and List responses whose body is not
+// a valid json document, as some gateways occasionally hand out truncated or garbled payloads.
+// It only has an effect together with WithRetry, which bounds the number of attempts.
+// It is off by default since retrying a payload that is persistently broken only wastes attempts.
+func WithRetryOnDeserializeError() ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		hac.retryOnDecodeError = true
+		return nil
+	}
+}
+
+// retryOutcome tells how sending with retries went, for the HTTPError of an operation failing nonetheless.
+type retryOutcome struct {
+	attempts int
+	elapsed  time.Duration
+	// outOfTime is set when retrying stopped short of the max attempts, see WithRetryMaxElapsed.
+	outOfTime bool
+}
+
+// annotate adds the number of attempts made to the message of e, along with the time taken
+// when retrying ran out of it.
+func (o retryOutcome) annotate(e *HTTPError) *HTTPError {
+	if o.outOfTime {
+		e.Message = fmt.Sprintf(msgRetryMaxElapsed, e.Message, o.attempts, o.elapsed)
+		return e
+	}
+	return withAttempts(o.attempts, e)
+}
+
+// sendWithRetry invokes send until it yields a non-retryable outcome or the retry policy is exhausted,
+// returning the last outcome along with how retrying went.
+// Only idempotent operations may be retried for reasons beyond transport and server failures.
+// Responses of the attempts given up on are drained and closed here, the last one is left to the caller.
+func (hac *httpAccountsClientImpl) sendWithRetry(ctx context.Context, idempotent bool, send func() (*http.Response, error)) (*http.Response, retryOutcome, error) {
+	maxAttempts := 1
+	if hac.retry != nil {
+		maxAttempts = hac.retry.maxAttempts
+	}
+
+	start := hac.clock.Now()
+	for attempt := 1; ; attempt++ {
+		resp, err := send()
+		outcome := retryOutcome{attempts: attempt, elapsed: hac.clock.Now().Sub(start)}
+		if attempt >= maxAttempts {
+			return resp, outcome, err
+		}
+		if !isRetryable(resp, err) && !(idempotent && hac.retryOnDecodeError && hac.isGarbled(resp)) {
+			return resp, outcome, err
+		}
+
+		delay := hac.retry.backoff(attempt, hac.retryMaxDelay)
+		if wait, ok := retryAfter(resp, hac.clock.Now()); ok {
+			delay = wait
+		}
+		if hac.retryMaxElapsed > 0 && outcome.elapsed+delay > hac.retryMaxElapsed {
+			outcome.outOfTime = true
+			return resp, outcome, err
+		}
+		discard(resp)
+
+		select {
+		case <-ctx.Done():
+			return nil, outcome, ctx.Err()
+		case <-hac.clock.After(delay):
+		}
+	}
+}
+
+// backoff returns the delay before the attempt following the given one: baseDelay doubled for every
+// attempt made so far, capped at maxDelay if positive, of which a random half is shaved off.
+func (rp *retryPolicy) backoff(attempt int, maxDelay time.Duration) time.Duration {
+	if rp.baseDelay <= 0 {
+		return 0
+	}
+	delay := rp.baseDelay << (attempt - 1)
+	// doubling often enough overflows, shifting back tells when it did
+	overflowed := attempt > 63 || delay>>(attempt-1) != rp.baseDelay
+	if maxDelay > 0 && (overflowed || delay > maxDelay) {
+		delay = maxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryAfter returns the delay a 429 response asks for in its Retry-After header, given either in seconds
+// or as an Http date, and whether there is such a delay at all. Dates before now amount to no delay.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	header := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		wait := date.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		// there is no point in retrying once the caller lost interest, nor a request that is never going to be sent,
+		// and a request turned away by a full queue is meant to fail fast
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, errDryRun) &&
+			!errors.Is(err, errQueueFull)
+	}
+	return resp != nil && retryableStatusCodes[resp.StatusCode]
+}
+
+// isGarbled tells whether a successful response carries a body that is not valid json.
+// The body is buffered, so that it can still be consumed by the caller when it is fine.
+func (hac *httpAccountsClientImpl) isGarbled(resp *http.Response) bool {
+	if resp == nil || resp.StatusCode != http.StatusOK {
+		return false
+	}
+	body, err := hac.readInput(io.LimitReader(resp.Body, hac.maxResponseBytes+1))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return true
+	}
+	decompressed, err := hac.decompress(resp, body)
+	return err != nil || !json.Valid(decompressed)
+}
+
+func discard(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+func withAttempts(attempts int, e *HTTPError) *HTTPError {
+	if attempts > 1 {
+		e.Message = fmt.Sprintf(msgAfterAttempts, e.Message, attempts)
+	}
+	return e
+}
@@ -0,0 +1,520 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithRetry_InvalidArguments(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithRetry(0, time.Millisecond))
+	if err == nil || err.Error() != "max attempts must be positive" {
+		t.Errorf("Expecting max attempts validation error, got=%v", err)
+	}
+
+	_, err = clientFactory.MakeClient("http://localhost:8080", WithRetry(3, -time.Millisecond))
+	if err == nil || err.Error() != "base delay must not be negative" {
+		t.Errorf("Expecting base delay validation error, got=%v", err)
+	}
+}
+
+func TestFetch_RetriesTransientStatusCodes(t *testing.T) {
+	statuses := []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[requests]
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+		}
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(3, time.Millisecond))
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Fetch(context.Background(), id.String())
+
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+	if requests != 3 {
+		t.Errorf("Expecting 3 requests, got=%d", requests)
+	}
+}
+
+func TestFetch_DoesNotRetryClientErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(3, time.Millisecond))
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Fetch(context.Background(), id.String())
+
+	emptyByteSlice := make([]byte, 0)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      404,
+		Status:          "404 Not Found",
+		Message:         "Unexpected response code returned for Get operation, expected 200, got 404",
+		Kind:            KindNotFound,
+		ResponsePayload: &emptyByteSlice,
+	})
+	assertAccountData(t, account, nil)
+	if requests != 1 {
+		t.Errorf("Expecting a single request, got=%d", requests)
+	}
+}
+
+func TestFetch_RetriesExhausted(t *testing.T) {
+	payload := []byte("still down")
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(3, time.Millisecond))
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Fetch(context.Background(), id.String())
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      503,
+		Status:          "503 Service Unavailable",
+		Message:         "Unexpected response code returned for Get operation, expected 200, got 503 (after 3 attempts)",
+		Kind:            KindServer,
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+	if requests != 3 {
+		t.Errorf("Expecting 3 requests, got=%d", requests)
+	}
+}
+
+func TestFetch_RetriesConnectionErrors(t *testing.T) {
+	err := errors.New("connection refused")
+	calls := 0
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithHttpGetter("http://localhost:8080",
+		func(ctx context.Context, path string) (*http.Response, error) {
+			calls++
+			return nil, err
+		}, WithRetry(4, time.Millisecond))
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Fetch(context.Background(), id.String())
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "Error placing Get Http request (after 4 attempts)",
+		Kind:    KindNetwork,
+		Cause:   err,
+	})
+	assertAccountData(t, account, nil)
+	if calls != 4 {
+		t.Errorf("Expecting 4 attempts, got=%d", calls)
+	}
+}
+
+func TestFetch_RetryStopsWhenContextDone(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(5, time.Second))
+	id, _ := uuid.NewUUID()
+	account, httpErr := client.Fetch(ctx, id.String())
+
+	if httpErr == nil || !errors.Is(httpErr.Cause, context.DeadlineExceeded) {
+		t.Fatalf("Expecting http error caused by context.DeadlineExceeded, got=%v", httpErr)
+	}
+	assertAccountData(t, account, nil)
+	if requests != 1 {
+		t.Errorf("Expecting a single request before the context expired, got=%d", requests)
+	}
+}
+
+func TestCreate_RetriesResendBody(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		requestBody, _ := io.ReadAll(r.Body)
+		if string(requestBody) != `{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}` {
+			t.Errorf("Unexpected request body on attempt %d, got=%s", requests, requestBody)
+		}
+		if requests == 1 {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(requestBody)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(2, time.Millisecond))
+	account, httpErr := client.Create(context.Background(), &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+	if requests != 2 {
+		t.Errorf("Expecting 2 requests, got=%d", requests)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 5, baseDelay: 100 * time.Millisecond}
+
+	for attempt, max := range map[int]time.Duration{1: 100, 2: 200, 3: 400} {
+		delay := policy.backoff(attempt, 0)
+		max = max * time.Millisecond
+		if delay < max/2 || delay > max {
+			t.Errorf("Backoff for attempt %d should be within [%s, %s], got=%s", attempt, max/2, max, delay)
+		}
+	}
+}
+
+func TestRetryPolicy_BackoffCapped(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 100, baseDelay: 100 * time.Millisecond}
+
+	for _, attempt := range []int{5, 10, 40, 99} {
+		delay := policy.backoff(attempt, time.Second)
+		if delay < 500*time.Millisecond || delay > time.Second {
+			t.Errorf("Backoff for attempt %d should be capped within [500ms, 1s], got=%s", attempt, delay)
+		}
+	}
+}
+
+func TestFetch_RetriesGarbledPayload(t *testing.T) {
+	payloads := []string{`{"data":{"id":"0d209d7f-d07a`, `{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(payloads[requests]))
+		requests++
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(3, time.Millisecond), WithRetryOnDeserializeError())
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+	if requests != 2 {
+		t.Errorf("Expecting 2 requests, got=%d", requests)
+	}
+}
+
+func TestFetch_GarbledPayloadNotRetriedByDefault(t *testing.T) {
+	payload := []byte(`{"data":{"id":"0d209d7f-d07a`)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(3, time.Millisecond))
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message:         "Error deserializing json",
+		Kind:            KindSerialization,
+		Cause:           errors.New("unexpected end of JSON input"),
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+	if requests != 1 {
+		t.Errorf("Expecting a single request, got=%d", requests)
+	}
+}
+
+func TestCreate_GarbledPayloadNeverRetried(t *testing.T) {
+	payload := []byte(`{"data":{"id":"0d209d7f-d07a`)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(3, time.Millisecond), WithRetryOnDeserializeError())
+	account, httpErr := client.Create(context.Background(), &AccountData{})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message:         "Error deserializing json",
+		Kind:            KindSerialization,
+		Cause:           errors.New("unexpected end of JSON input"),
+		ResponsePayload: &payload,
+	})
+	assertAccountData(t, account, nil)
+	if requests != 1 {
+		t.Errorf("Expecting a single request, got=%d", requests)
+	}
+}
+
+func TestWithRetry_CreateAttemptsShareIdempotencyKey(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(2, 0))
+	_, httpErr := client.Create(context.Background(), &AccountData{})
+
+	assertHttpError(t, httpErr, nil)
+	if len(keys) != 2 {
+		t.Fatalf("Expecting 2 attempts, got=%d", len(keys))
+	}
+	if keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("Expecting both attempts to carry the same generated key, got=%v", keys)
+	}
+}
+
+func TestFetch_TooManyRequestsWithoutRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	emptyPayload := make([]byte, 0)
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      429,
+		Status:          "429 Too Many Requests",
+		Message:         "Unexpected response code returned for Get operation, expected 200, got 429",
+		Kind:            KindValidation,
+		RetryAfter:      2 * time.Second,
+		ResponsePayload: &emptyPayload,
+	})
+	assertAccountData(t, account, nil)
+}
+
+func TestWithRetry_TooManyRequestsHonoursRetryAfter(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	// Retry-After is preferred over the backoff, which would otherwise hold the test for an hour
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(2, time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, httpErr := client.Fetch(ctx, "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	if attempts != 2 {
+		t.Errorf("Expecting 2 attempts, got=%d", attempts)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		header   string
+		expected time.Duration
+		atLeast  time.Duration
+	}{
+		{"delta seconds", 429, "2", 2 * time.Second, 0},
+		{"zero", 429, "0", 0, 0},
+		{"negative", 429, "-1", 0, 0},
+		{"missing", 429, "", 0, 0},
+		{"garbage", 429, "soon", 0, 0},
+		{"date in the past", 429, "Wed, 21 Oct 2015 07:28:00 GMT", 0, 0},
+		{"date in the future", 429, time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), -1, 59 * time.Minute},
+		{"not a 429", 503, "2", 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: test.status, Header: http.Header{}}
+			if test.header != "" {
+				resp.Header.Set("Retry-After", test.header)
+			}
+			actual, _ := retryAfter(resp, time.Now())
+			if test.expected >= 0 && actual != test.expected {
+				t.Errorf("Expecting=%s, got=%s", test.expected, actual)
+			}
+			if actual < test.atLeast {
+				t.Errorf("Expecting at least %s, got=%s", test.atLeast, actual)
+			}
+		})
+	}
+}
+
+func TestFetch_RetryBackoffGoesThroughClock(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	clientFactory := AccountsHttpClientFactory{}
+	// an hour long backoff would time the test out, were it not for the fake clock
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(3, time.Hour), WithClock(clock))
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	assertAccountData(t, account, &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"})
+	delays := clock.Delays()
+	if len(delays) != 2 ||
+		delays[0] < 30*time.Minute || delays[0] > time.Hour ||
+		delays[1] < time.Hour || delays[1] > 2*time.Hour {
+		t.Errorf("Expecting a jittered exponential backoff of 1h then 2h, got=%v", delays)
+	}
+}
+
+func TestFetch_RetryAfterDateMeasuredByClock(t *testing.T) {
+	clock := newFakeClock()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", clock.Now().Add(90*time.Second).Format(http.TimeFormat))
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithRetry(2, time.Millisecond), WithClock(clock))
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	if delays := clock.Delays(); len(delays) != 1 || delays[0] != 90*time.Second {
+		t.Errorf("Expecting a single 90s wait, got=%v", delays)
+	}
+}
+
+func TestFetch_RetryAfterDateReportedByClock(t *testing.T) {
+	clock := newFakeClock()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", clock.Now().Add(45*time.Second).Format(http.TimeFormat))
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithClock(clock))
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	if httpErr == nil || httpErr.RetryAfter != 45*time.Second {
+		t.Errorf("Expecting RetryAfter=45s measured by the clock, got=%v", httpErr)
+	}
+}
+
+func TestWithClock_Nil(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithClock(nil))
+	if err == nil || err.Error() != "clock must not be nil" {
+		t.Errorf("Expecting clock validation error, got=%v", err)
+	}
+}
+
+func TestFetch_RetryGivesUpAtMaxElapsed(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	clientFactory := AccountsHttpClientFactory{}
+	// the first backoff of at most 1h fits in, the second one of at least 1h doesn't once at least 30m have elapsed
+	client, _ := clientFactory.MakeClient(server.URL,
+		WithRetry(10, time.Hour), WithRetryMaxElapsed(89*time.Minute), WithClock(clock))
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	if requests != 2 || len(clock.Delays()) != 1 {
+		t.Errorf("Expecting 2 requests and a single wait, got %d requests and waits %v", requests, clock.Delays())
+	}
+	expected := fmt.Sprintf("Unexpected response code returned for Get operation, expected 200, got 503 "+
+		"(gave up after 2 attempts in %s, the next one would exceed the max elapsed time)", clock.Delays()[0])
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      503,
+		Status:          "503 Service Unavailable",
+		Message:         expected,
+		Kind:            KindServer,
+		ResponsePayload: &[]byte{},
+	})
+}
+
+func TestWithRetryMaxDelayAndElapsed_NotPositive(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithRetryMaxDelay(0))
+	if err == nil || err.Error() != "max retry delay must be positive" {
+		t.Errorf("Expecting max retry delay validation error, got=%v", err)
+	}
+	_, err = clientFactory.MakeClient("http://localhost:8080", WithRetryMaxElapsed(-time.Second))
+	if err == nil || err.Error() != "max retry elapsed time must be positive" {
+		t.Errorf("Expecting max retry elapsed time validation error, got=%v", err)
+	}
+}
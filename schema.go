@@ -0,0 +1,87 @@
+package interview_accountapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"strings"
+)
+
+// schemaResource is the made up location the schema is registered under, it is never fetched.
+const schemaResource = "mem:///account.schema.json"
+
+// WithSchemaValidation validates the payload of every Create and Update against the given JSON Schema
+// before it is sent, failing with a KindValidation error listing the violations instead.
+// The schema applies to the whole envelope, i.e. the account lives under the data property.
+// Validation runs on the exact bytes that would go over the wire, so it sees what the server would.
+func WithSchemaValidation(schema []byte) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if len(schema) == 0 {
+			return errors.New("schema must not be empty")
+		}
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource(schemaResource, bytes.NewReader(schema)); err != nil {
+			return fmt.Errorf("invalid schema provided: %w", err)
+		}
+		compiled, err := compiler.Compile(schemaResource)
+		if err != nil {
+			return fmt.Errorf("invalid schema provided: %w", err)
+		}
+		hac.schema = compiled
+		return nil
+	}
+}
+
+// validateSchema checks the serialized request payload against the configured schema, if any.
+func (hac *httpAccountsClientImpl) validateSchema(requestData []byte) *HTTPError {
+	if hac.schema == nil {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(requestData))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return &HTTPError{
+			Cause:   err,
+			Message: msgDecodingForSchema,
+			Kind:    KindSerialization,
+		}
+	}
+
+	err := hac.schema.Validate(document)
+	if err == nil {
+		return nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return &HTTPError{
+			Cause:   err,
+			Message: msgValidatingSchema,
+			Kind:    KindValidation,
+		}
+	}
+	return &HTTPError{
+		Cause:   err,
+		Message: fmt.Sprintf(msgSchemaViolations, strings.Join(violations(validationErr), "; ")),
+		Kind:    KindValidation,
+	}
+}
+
+// violations flattens a validation error into one line per failed leaf, e.g. "/data: missing properties: 'organisation_id'".
+func violations(ve *jsonschema.ValidationError) []string {
+	if len(ve.Causes) == 0 {
+		location := ve.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		return []string{fmt.Sprintf("%s: %s", location, ve.Message)}
+	}
+	var lines []string
+	for _, cause := range ve.Causes {
+		lines = append(lines, violations(cause)...)
+	}
+	return lines
+}
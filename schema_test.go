@@ -0,0 +1,108 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var organisationRequiredSchema = []byte(`{
+	"type": "object",
+	"required": ["data"],
+	"properties": {
+		"data": {
+			"type": "object",
+			"required": ["organisation_id"],
+			"properties": {
+				"organisation_id": {"type": "string", "format": "uuid"}
+			}
+		}
+	}
+}`)
+
+func TestCreate_SchemaValidation_MissingOrganisationID(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, err := clientFactory.MakeClient(server.URL, WithSchemaValidation(organisationRequiredSchema))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	account, httpErr := client.Create(context.Background(), &AccountData{
+		ID:   "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Type: "accounts",
+	})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:   errors.New("jsonschema: '/data' does not validate with mem:///account.schema.json#/properties/data/required: missing properties: 'organisation_id'"),
+		Message: "payload failed schema validation: /data: missing properties: 'organisation_id'",
+		Kind:    KindValidation,
+	})
+	assertAccountData(t, account, nil)
+	if requests != 0 {
+		t.Errorf("Expecting the request not to be sent, got %d requests", requests)
+	}
+}
+
+func TestCreate_SchemaValidation_Valid(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithSchemaValidation(organisationRequiredSchema))
+	_, httpErr := client.Create(context.Background(), &AccountData{
+		ID:             "0d209d7f-d07a-4542-947f-5885fddddae2",
+		OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43",
+	})
+
+	assertHttpError(t, httpErr, nil)
+	if requests != 1 {
+		t.Errorf("Expecting a single request, got=%d", requests)
+	}
+}
+
+func TestUpdate_SchemaValidation_MissingOrganisationID(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithSchemaValidation(organisationRequiredSchema))
+	_, httpErr := client.Update(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0, &AccountData{
+		Attributes: &AccountAttributes{Bic: "NWBKGB22"},
+	})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:   errors.New("jsonschema: '/data' does not validate with mem:///account.schema.json#/properties/data/required: missing properties: 'organisation_id'"),
+		Message: "payload failed schema validation: /data: missing properties: 'organisation_id'",
+		Kind:    KindValidation,
+	})
+	if requests != 0 {
+		t.Errorf("Expecting the request not to be sent, got %d requests", requests)
+	}
+}
+
+func TestWithSchemaValidation_InvalidSchema(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	for _, schema := range [][]byte{nil, []byte(`{"type": `), []byte(`{"type": 42}`)} {
+		if _, err := clientFactory.MakeClient("http://localhost:8080", WithSchemaValidation(schema)); err == nil {
+			t.Errorf("Expecting schema %q to be rejected", schema)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package interview_accountapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// scrubbedValue replaces the values of scrubbed fields.
+const scrubbedValue = "***"
+
+// defaultScrubbedFields are the fields masked by WithFieldScrubber when it's given none.
+var defaultScrubbedFields = []string{"iban", "account_number", "bic"}
+
+// fieldScrubber holds the names of the json fields to be masked, a nil scrubber masks nothing.
+type fieldScrubber map[string]bool
+
+// WithFieldScrubber masks the values of the given json fields, by default iban, account_number and bic,
+// in the response payloads logged by WithBodyLogging and in the ResponsePayload of the errors returned by the client,
+// which also shows in Error() with WithErrorPayloadInMessage.
+// Fields are matched by name at any depth, payloads that aren't json are left as they are.
+func WithFieldScrubber(fields ...string) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if len(fields) == 0 {
+			fields = defaultScrubbedFields
+		}
+		hac.scrubber = fieldScrubber{}
+		for _, field := range fields {
+			hac.scrubber[field] = true
+		}
+		return nil
+	}
+}
+
+// scrub returns a copy of data with the values of the scrubbed fields masked,
+// or data itself if there is nothing to scrub or data is not json.
+// Since the payload gets decoded and encoded again, keys come out sorted and whitespace is dropped.
+func (s fieldScrubber) scrub(data []byte) []byte {
+	if len(s) == 0 || len(data) == 0 {
+		return data
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return data
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return data
+	}
+	scrubbed, err := json.Marshal(s.mask(document))
+	if err != nil {
+		return data
+	}
+	return scrubbed
+}
+
+func (s fieldScrubber) mask(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, member := range v {
+			if s[key] {
+				v[key] = scrubbedValue
+				continue
+			}
+			v[key] = s.mask(member)
+		}
+	case []any:
+		for i, element := range v {
+			v[i] = s.mask(element)
+		}
+	}
+	return value
+}
@@ -0,0 +1,102 @@
+package interview_accountapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFieldScrubber_Scrub(t *testing.T) {
+	scrubber := fieldScrubber{"iban": true, "bic": true}
+	tests := map[string]struct {
+		payload  string
+		expected string
+	}{
+		"nested": {
+			payload:  `{"data":{"attributes":{"iban":"GB33BUKB20201555555555","bic":"NWBKGB22","country":"GB"}}}`,
+			expected: `{"data":{"attributes":{"bic":"***","country":"GB","iban":"***"}}}`,
+		},
+		"within arrays": {
+			payload:  `{"data":[{"attributes":{"iban":"GB33BUKB20201555555555"}},{"attributes":{}}]}`,
+			expected: `{"data":[{"attributes":{"iban":"***"}},{"attributes":{}}]}`,
+		},
+		"numbers kept verbatim": {
+			payload:  `{"version":10000000000000000001,"iban":null}`,
+			expected: `{"iban":"***","version":10000000000000000001}`,
+		},
+		"nothing to scrub": {
+			payload:  `{"error_message":"record does not exist"}`,
+			expected: `{"error_message":"record does not exist"}`,
+		},
+		"not json": {
+			payload:  `<html>iban GB33BUKB20201555555555</html>`,
+			expected: `<html>iban GB33BUKB20201555555555</html>`,
+		},
+		"trailing data": {
+			payload:  `{"iban":"GB33BUKB20201555555555"} {}`,
+			expected: `{"iban":"GB33BUKB20201555555555"} {}`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scrubbed := string(scrubber.scrub([]byte(test.payload)))
+			if scrubbed != test.expected {
+				t.Errorf("Expecting=%s, got=%s", test.expected, scrubbed)
+			}
+		})
+	}
+}
+
+func TestFieldScrubber_Nil(t *testing.T) {
+	var scrubber fieldScrubber
+	payload := `{"iban":"GB33BUKB20201555555555"}`
+	if scrubbed := string(scrubber.scrub([]byte(payload))); scrubbed != payload {
+		t.Errorf("Expecting the payload untouched, got=%s", scrubbed)
+	}
+}
+
+func TestWithFieldScrubber_MasksErrorPayload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error_message":"duplicate","data":{"attributes":{"iban":"GB33BUKB20201555555555"}}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithErrorPayloadInMessage(1024), WithFieldScrubber())
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	expected := "409 Conflict: Unexpected response code returned for Get operation, expected 200, got 409" +
+		` : duplicate : {"data":{"attributes":{"iban":"***"}},"error_message":"duplicate"}`
+	if httpErr == nil || httpErr.Error() != expected {
+		t.Errorf("Expecting error=%s, got=%v", expected, httpErr)
+	}
+	if strings.Contains(string(*httpErr.ResponsePayload), "GB33BUKB20201555555555") {
+		t.Errorf("Expecting the iban masked in the response payload, got=%s", *httpErr.ResponsePayload)
+	}
+}
+
+func TestWithFieldScrubber_MasksLoggedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","attributes":{"account_number":"41426819"}}}`))
+	}))
+	defer server.Close()
+
+	logger := &capturingLogger{}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithLogger(logger), WithBodyLogging(), WithFieldScrubber("account_number"))
+	account, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	if len(logger.debugs) != 2 || !strings.HasSuffix(logger.debugs[1], `response body: {"data":{"attributes":{"account_number":"***"},"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`) {
+		t.Errorf("Expecting the account number masked in the logged body, got=%v", logger.debugs)
+	}
+	if account.Attributes == nil || account.Attributes.AccountNumber != "41426819" {
+		t.Errorf("Expecting the account itself untouched, got=%+v", account.Attributes)
+	}
+}
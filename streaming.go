@@ -0,0 +1,168 @@
+package interview_accountapi
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// payloadSampleBytes bounds how much of a streamed body is kept to be reported along with a failure to decode it.
+const payloadSampleBytes = 4 << 10
+
+var errTrailingData = errors.New("invalid data after top-level value")
+
+// canStream tells whether list pages can be decoded straight off the wire rather than read in full first.
+// Bodies to be logged, or handed to a custom reader or deserializer, still need reading in full.
+func (hac *httpAccountsClientImpl) canStream() bool {
+	return hac.streaming && !hac.logBodies
+}
+
+// streamListPage is the counterpart of listPage decoding the page as it arrives, which spares holding a copy of the
+// whole body next to the decoded accounts. Only a body carrying an unexpected status code gets read in full,
+// to be reported in the error, a body failing to decode is reported by its first payloadSampleBytes only.
+func (hac *httpAccountsClientImpl) streamListPage(ctx context.Context, path string) (*ListEnvelope[AccountData], *HTTPError) {
+	resp, outcome, err := hac.sendWithRetry(ctx, true, func() (*http.Response, error) {
+		return hac.doHttpGet(ctx, path)
+	})
+	if err != nil {
+		return nil,
+			outcome.annotate(placingError(err, "Get"))
+	}
+
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if !hac.succeeded(http.StatusOK, resp, "List") {
+		responseData, httpErr := hac.readPayload(resp)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+		return nil,
+			outcome.annotate(hac.unexpectedStatusCode(http.StatusOK, resp, "List", responseData))
+	}
+
+	httpErr := expectJsonContentType(resp, nil)
+	if httpErr == nil {
+		httpErr = hac.expectSchemaVersion(resp, nil)
+	}
+	if httpErr != nil {
+		if responseData, readErr := hac.readPayload(resp); readErr == nil {
+			httpErr.ResponsePayload = responseData
+		}
+		return nil, httpErr
+	}
+
+	return hac.decodeListEnvelope(resp)
+}
+
+// decodeListEnvelope decodes the body of resp into a list envelope, enforcing the same limits as readPayload.
+func (hac *httpAccountsClientImpl) decodeListEnvelope(resp *http.Response) (*ListEnvelope[AccountData], *HTTPError) {
+	body := &countingReader{r: resp.Body}
+	var input io.Reader = body
+	if isGzipped(resp) {
+		inflated, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, streamReadError(resp, body, &HTTPError{
+				Cause:   err,
+				Message: msgDecompressingBody,
+				Kind:    KindSerialization,
+			})
+		}
+		defer inflated.Close()
+		input = inflated
+	}
+	// the limit applies to the inflated body, as in readPayload
+	limited := &countingReader{r: io.LimitReader(input, hac.maxResponseBytes+1)}
+
+	sample := &sampleWriter{}
+	decoder := json.NewDecoder(io.TeeReader(limited, sample))
+	var responseEnvelope *ListEnvelope[AccountData]
+	err := decoder.Decode(&responseEnvelope)
+	if err == nil {
+		// json.Unmarshal rejects anything following the envelope, so does the decoder
+		if _, tokenErr := decoder.Token(); tokenErr != io.EOF {
+			err = errTrailingData
+		}
+	}
+
+	if limited.n > hac.maxResponseBytes {
+		return nil, &HTTPError{
+			Cause:   errResponseTooLarge,
+			Message: msgResponseTooLarge,
+			Kind:    KindNetwork,
+		}
+	}
+	if body.err != nil || limited.err != nil {
+		return nil, streamReadError(resp, body, &HTTPError{
+			Cause:   limited.err,
+			Message: msgDecompressingBody,
+			Kind:    KindSerialization,
+		})
+	}
+	if err != nil || responseEnvelope == nil {
+		return nil, &HTTPError{
+			Cause:           dataShapeError(err, "array"),
+			Message:         msgDeserializing,
+			Kind:            KindSerialization,
+			ResponsePayload: &sample.data,
+		}
+	}
+	hac.countReceived(resp, body.n, limited.n)
+	return responseEnvelope, nil
+}
+
+// streamReadError reports a failure to read the body off the wire the way readPayload does, a failure to make sense
+// of what was read, e.g. of inflating it, is reported as given.
+func streamReadError(resp *http.Response, body *countingReader, httpErr *HTTPError) *HTTPError {
+	if body.err == nil {
+		return httpErr
+	}
+	if errors.Is(body.err, io.ErrUnexpectedEOF) && hasDeclaredLength(resp) {
+		return &HTTPError{
+			Cause:   body.err,
+			Message: msgTruncatedBody,
+			Kind:    KindNetwork,
+		}
+	}
+	return &HTTPError{
+		Cause:   body.err,
+		Message: msgProcessingBody,
+		Kind:    KindNetwork,
+	}
+}
+
+// countingReader counts the bytes read through it and keeps the first error other than io.EOF,
+// so that a failing read can be told apart from a payload failing to decode.
+type countingReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if err != nil && err != io.EOF && c.err == nil {
+		c.err = err
+	}
+	return n, err
+}
+
+// sampleWriter keeps the first payloadSampleBytes written to it and discards the rest.
+type sampleWriter struct {
+	data []byte
+}
+
+func (s *sampleWriter) Write(p []byte) (int, error) {
+	if room := payloadSampleBytes - len(s.data); room > 0 {
+		if len(p) < room {
+			room = len(p)
+		}
+		s.data = append(s.data, p[:room]...)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,201 @@
+package interview_accountapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// listPayload builds a page of n accounts, as served by the accounts API.
+func listPayload(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"data":[`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `{"id":"0d209d7f-d07a-4542-947f-%012d","organisation_id":"ba61483c-d5c5-4f50-ae81-6b8c039bea43",`+
+			`"type":"accounts","version":0,"attributes":{"country":"GB","bank_id":"400300","bank_id_code":"GBDSC",`+
+			`"bic":"NWBKGB22","name":["Jane Doe"],"account_number":"41426819","iban":"GB11NWBK40030041426819"}}`, i)
+	}
+	buf.WriteString(`],"links":{"self":"/v1/organisation/accounts"}}`)
+	return buf.Bytes()
+}
+
+func listServer(payload []byte, gzipped bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if gzipped {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.WriteHeader(http.StatusOK)
+			writer := gzip.NewWriter(w)
+			writer.Write(payload)
+			writer.Close()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(payload)
+	}))
+}
+
+func TestList_Streamed(t *testing.T) {
+	for _, gzipped := range []bool{false, true} {
+		t.Run(fmt.Sprintf("gzipped=%t", gzipped), func(t *testing.T) {
+			server := listServer(listPayload(3), gzipped)
+			defer server.Close()
+
+			clientFactory := AccountsHttpClientFactory{}
+			client, _ := clientFactory.MakeClient(server.URL)
+			accounts, links, httpErr := client.List(context.Background(), 0, 3)
+
+			assertHttpError(t, httpErr, nil)
+			if len(accounts) != 3 || accounts[2].ID != "0d209d7f-d07a-4542-947f-000000000002" {
+				t.Errorf("Expecting 3 accounts, got=%v", accounts)
+			}
+			if links.Self != "/v1/organisation/accounts" {
+				t.Errorf("Expecting the self link, got=%s", links.Self)
+			}
+		})
+	}
+}
+
+func TestList_StreamedDataNotArray(t *testing.T) {
+	payload := []byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`)
+	server := listServer(payload, false)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	_, _, httpErr := client.List(context.Background(), 0, 10)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:           errors.New("expecting data to be an array, got an object"),
+		Message:         "Error deserializing json",
+		Kind:            KindSerialization,
+		ResponsePayload: &payload,
+	})
+}
+
+func TestList_StreamedTrailingData(t *testing.T) {
+	payload := []byte(`{"data":[]} {}`)
+	server := listServer(payload, false)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	_, _, httpErr := client.List(context.Background(), 0, 10)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:           errTrailingData,
+		Message:         "Error deserializing json",
+		Kind:            KindSerialization,
+		ResponsePayload: &payload,
+	})
+}
+
+func TestList_StreamedPayloadSampled(t *testing.T) {
+	payload := append(listPayload(50), '}')
+	server := listServer(payload, false)
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	_, _, httpErr := client.List(context.Background(), 0, 50)
+
+	if httpErr == nil || httpErr.Message != "Error deserializing json" {
+		t.Fatalf("Expecting a deserialization error, got=%v", httpErr)
+	}
+	if sample := *httpErr.ResponsePayload; len(sample) != payloadSampleBytes || !bytes.HasPrefix(payload, sample) {
+		t.Errorf("Expecting the first %d bytes of the payload, got %d bytes", payloadSampleBytes, len(sample))
+	}
+}
+
+func TestList_StreamedBodyOverLimit(t *testing.T) {
+	for _, gzipped := range []bool{false, true} {
+		t.Run(fmt.Sprintf("gzipped=%t", gzipped), func(t *testing.T) {
+			server := listServer(listPayload(10), gzipped)
+			defer server.Close()
+
+			clientFactory := AccountsHttpClientFactory{}
+			client, _ := clientFactory.MakeClient(server.URL, WithMaxResponseBytes(1024))
+			_, _, httpErr := client.List(context.Background(), 0, 10)
+
+			assertHttpError(t, httpErr, &HTTPError{
+				Cause:   errResponseTooLarge,
+				Message: "response body exceeded max size",
+				Kind:    KindNetwork,
+			})
+		})
+	}
+}
+
+func TestList_StreamedContentTypeNotJson(t *testing.T) {
+	payload := []byte(`<html>oops</html>`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusOK)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	_, _, httpErr := client.List(context.Background(), 0, 10)
+
+	assertHttpError(t, httpErr, &HTTPError{
+		StatusCode:      200,
+		Status:          "200 OK",
+		Message:         "Unexpected Content-Type, expecting application/json, got text/html",
+		Kind:            KindSerialization,
+		ResponsePayload: &payload,
+	})
+}
+
+func TestList_TruncatedStreamedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "100")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":[`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	_, _, httpErr := client.List(context.Background(), 0, 10)
+
+	if httpErr == nil || httpErr.Message != "truncated response body" || !httpErr.IsNetwork() {
+		t.Errorf("Expecting a truncated body error, got=%v", httpErr)
+	}
+}
+
+func BenchmarkListPage(b *testing.B) {
+	payload := listPayload(maxPageSize)
+	server := listServer(payload, false)
+	defer server.Close()
+
+	for _, streaming := range []bool{false, true} {
+		name := "buffered"
+		if streaming {
+			name = "streamed"
+		}
+		b.Run(name, func(b *testing.B) {
+			clientFactory := AccountsHttpClientFactory{}
+			client, _ := clientFactory.MakeClient(server.URL)
+			client.(*httpAccountsClientImpl).streaming = streaming
+			b.SetBytes(int64(len(payload)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, httpErr := client.(*httpAccountsClientImpl).listPage(context.Background(), server.URL); httpErr != nil {
+					b.Fatal(httpErr)
+				}
+			}
+		})
+	}
+}
@@ -1,8 +1,8 @@
 package interview_accountapi
 
 import (
-	"fmt"
-	"testing"
+	"sync"
+	"time"
 )
 
 func assertPrimitiveSlices[T string | int | byte](a, b []T) bool {
@@ -30,19 +30,44 @@ func assertPrimitiveSlices[T string | int | byte](a, b []T) bool {
 	return true
 }
 
-func assertPrimitivePointers[P string | bool | int | int64 | byte](t *testing.T, actual *P, expected *P, varName string) {
-	if actual == nil && expected != nil {
-		t.Errorf("Actual %s should not be nil", varName)
-	}
+// fakeClock is a Clock whose time only moves when something sleeps or waits on it, and then instantly,
+// recording every delay it was asked for.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	delays []time.Duration
+}
 
-	if actual != nil && expected == nil {
-		t.Errorf("Actual %s payload should be nil", varName)
-	}
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
 
-	if actual != nil && expected != nil {
-		if *actual != *expected {
-			t.Errorf("%s doesn't match, expected=%s, got=%s", varName,
-				fmt.Sprintf("%v", *expected), fmt.Sprintf("%v", *actual))
-		}
-	}
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.advance(d)
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.advance(d)
+	return ch
+}
+
+func (c *fakeClock) advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.delays = append(c.delays, d)
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+func (c *fakeClock) Delays() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.delays...)
 }
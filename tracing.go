@@ -0,0 +1,80 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+)
+
+// Tracer starts the spans client operations get wrapped in, e.g. an adapter over an OpenTelemetry tracer.
+// The returned context is the one the operation carries on with, so it may carry the span for propagation.
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is the part of a tracing span the client needs, it sets the following attributes:
+// "http.method" and "http.status_code" of the last Http request sent and "error.kind" when the operation fails,
+// as well as "request.id" with WithResponseObservabilityContext.
+type Span interface {
+	SetAttribute(key string, value any)
+	End()
+}
+
+type noopTracer struct{}
+
+func (noopTracer) StartSpan(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, any) {}
+func (noopSpan) End()                     {}
+
+type spanKey struct{}
+
+// WithTracer wraps every operation placing requests of its own, i.e. Fetch, FetchHead, Exists, Create, CreateFromReader,
+// Update, Delete, List, ListFiltered, ListAll, DoRaw, HealthCheck, Ping and Warmup, in a span named after it,
+// e.g. "accounts.Fetch". Operations built on others, e.g. FetchThenDelete, show as the spans of those.
+func WithTracer(tracer Tracer) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		if tracer == nil {
+			return errors.New("tracer must not be nil")
+		}
+		hac.tracer = tracer
+		return nil
+	}
+}
+
+// startSpan starts the span of an operation, the returned finalizer is meant to be deferred
+// so that the span ends however the operation returns.
+// The operation's own deadline, see WithOperationTimeout, is applied here too, as every operation placing requests
+// of its own starts a span.
+func (hac *httpAccountsClientImpl) startSpan(ctx context.Context, operation string) (context.Context, func(*HTTPError)) {
+	ctx, cancel := hac.withOperationTimeout(ctx, operation)
+	ctx, span := hac.tracer.StartSpan(ctx, "accounts."+operation)
+	ctx = context.WithValue(ctx, spanKey{}, span)
+	return ctx, func(httpErr *HTTPError) {
+		if httpErr != nil {
+			span.SetAttribute("error.kind", httpErr.Kind.String())
+		}
+		span.End()
+		cancel()
+		// every operation reporting response payloads starts a span, making this the place to describe them
+		hac.describePayload(httpErr)
+	}
+}
+
+// traceRequest records a request on the span of the operation it was sent for.
+func (hac *httpAccountsClientImpl) traceRequest(o *observation) {
+	span, ok := o.req.Context().Value(spanKey{}).(Span)
+	if !ok {
+		return
+	}
+	span.SetAttribute("http.method", o.req.Method)
+	if o.resp != nil {
+		span.SetAttribute("http.status_code", o.resp.StatusCode)
+	}
+	if hac.correlate && o.requestID != "" {
+		span.SetAttribute("request.id", o.requestID)
+	}
+}
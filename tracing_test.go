@@ -0,0 +1,155 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type recordedSpan struct {
+	name       string
+	attributes map[string]any
+	ended      int
+}
+
+func (s *recordedSpan) SetAttribute(key string, value any) {
+	s.attributes[key] = value
+}
+
+func (s *recordedSpan) End() {
+	s.ended++
+}
+
+type inMemoryTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *inMemoryTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &recordedSpan{name: name, attributes: map[string]any{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func assertSpan(t *testing.T, actual *recordedSpan, name string, attributes map[string]any) {
+	t.Helper()
+	if actual.name != name {
+		t.Errorf("Expecting span %s, got=%s", name, actual.name)
+	}
+	if actual.ended != 1 {
+		t.Errorf("Expecting span %s to end once, got=%d", name, actual.ended)
+	}
+	if len(actual.attributes) != len(attributes) {
+		t.Errorf("Expecting span attributes=%v, got=%v", attributes, actual.attributes)
+	}
+	for key, value := range attributes {
+		if actual.attributes[key] != value {
+			t.Errorf("Expecting span attribute %s=%v, got=%v", key, value, actual.attributes[key])
+		}
+	}
+}
+
+func TestWithTracer_FetchSucceeded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2"}}`))
+	}))
+	defer server.Close()
+
+	tracer := &inMemoryTracer{}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithTracer(tracer))
+	_, httpErr := client.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+
+	assertHttpError(t, httpErr, nil)
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Expecting a single span, got=%d", len(tracer.spans))
+	}
+	assertSpan(t, tracer.spans[0], "accounts.Fetch", map[string]any{
+		"http.method":      "GET",
+		"http.status_code": 200,
+	})
+}
+
+func TestWithTracer_DeleteFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+
+	tracer := &inMemoryTracer{}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithTracer(tracer))
+	client.Delete(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 0)
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Expecting a single span, got=%d", len(tracer.spans))
+	}
+	assertSpan(t, tracer.spans[0], "accounts.Delete", map[string]any{
+		"http.method":      "DELETE",
+		"http.status_code": 409,
+		"error.kind":       "conflict",
+	})
+}
+
+func TestWithTracer_CreateNetworkFailure(t *testing.T) {
+	tracer := &inMemoryTracer{}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeTestClientWithRequestInvoker("http://localhost:8080", func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}, WithTracer(tracer))
+	client.Create(context.Background(), &AccountData{})
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Expecting a single span, got=%d", len(tracer.spans))
+	}
+	assertSpan(t, tracer.spans[0], "accounts.Create", map[string]any{
+		"http.method": "POST",
+		"error.kind":  "network",
+	})
+}
+
+func TestWithTracer_EarlyValidationFailure(t *testing.T) {
+	tracer := &inMemoryTracer{}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient("http://localhost:8080", WithTracer(tracer))
+	client.Fetch(context.Background(), "blah")
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Expecting a single span, got=%d", len(tracer.spans))
+	}
+	assertSpan(t, tracer.spans[0], "accounts.Fetch", map[string]any{
+		"error.kind": "validation",
+	})
+}
+
+func TestWithTracer_HeadAndHealthOperations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tracer := &inMemoryTracer{}
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithTracer(tracer))
+	client.FetchHead(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	client.Exists(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	client.HealthCheck(context.Background())
+	client.Ping(context.Background())
+	client.Warmup(context.Background(), 1)
+
+	if len(tracer.spans) != 5 {
+		t.Fatalf("Expecting a span per operation, got=%d", len(tracer.spans))
+	}
+	assertSpan(t, tracer.spans[0], "accounts.FetchHead", map[string]any{"http.method": "HEAD", "http.status_code": 200})
+	assertSpan(t, tracer.spans[1], "accounts.Exists", map[string]any{"http.method": "HEAD", "http.status_code": 200})
+	assertSpan(t, tracer.spans[2], "accounts.HealthCheck", map[string]any{"http.method": "GET", "http.status_code": 200})
+	assertSpan(t, tracer.spans[3], "accounts.Ping", map[string]any{"http.method": "GET", "http.status_code": 200})
+	assertSpan(t, tracer.spans[4], "accounts.Warmup", map[string]any{"http.method": "HEAD", "http.status_code": 200})
+}
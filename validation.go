@@ -0,0 +1,253 @@
+package interview_accountapi
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ibanLengths holds the ISO 13616 IBAN length of every country in the IBAN registry.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22,
+	"BH": 22, "BI": 27, "BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24,
+	"DE": 22, "DJ": 27, "DK": 18, "DO": 28, "EE": 20, "EG": 29, "ES": 24, "FI": 18,
+	"FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28,
+	"HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27, "JO": 30,
+	"KW": 30, "KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21,
+	"LY": 25, "MC": 27, "MD": 24, "ME": 22, "MK": 19, "MR": 27, "MT": 31, "MU": 30,
+	"NL": 18, "NO": 15, "PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24,
+	"RS": 22, "RU": 33, "SA": 24, "SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24,
+	"SM": 27, "ST": 25, "SV": 28, "TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22,
+	"VG": 24, "XK": 20,
+}
+
+// countryCodes holds the ISO 3166-1 alpha-2 officially assigned country codes.
+var countryCodes = map[string]struct{}{
+	"AD": {}, "AE": {}, "AF": {}, "AG": {}, "AI": {}, "AL": {}, "AM": {}, "AO": {}, "AQ": {}, "AR": {}, "AS": {}, "AT": {},
+	"AU": {}, "AW": {}, "AX": {}, "AZ": {}, "BA": {}, "BB": {}, "BD": {}, "BE": {}, "BF": {}, "BG": {}, "BH": {}, "BI": {},
+	"BJ": {}, "BL": {}, "BM": {}, "BN": {}, "BO": {}, "BQ": {}, "BR": {}, "BS": {}, "BT": {}, "BV": {}, "BW": {}, "BY": {},
+	"BZ": {}, "CA": {}, "CC": {}, "CD": {}, "CF": {}, "CG": {}, "CH": {}, "CI": {}, "CK": {}, "CL": {}, "CM": {}, "CN": {},
+	"CO": {}, "CR": {}, "CU": {}, "CV": {}, "CW": {}, "CX": {}, "CY": {}, "CZ": {}, "DE": {}, "DJ": {}, "DK": {}, "DM": {},
+	"DO": {}, "DZ": {}, "EC": {}, "EE": {}, "EG": {}, "EH": {}, "ER": {}, "ES": {}, "ET": {}, "FI": {}, "FJ": {}, "FK": {},
+	"FM": {}, "FO": {}, "FR": {}, "GA": {}, "GB": {}, "GD": {}, "GE": {}, "GF": {}, "GG": {}, "GH": {}, "GI": {}, "GL": {},
+	"GM": {}, "GN": {}, "GP": {}, "GQ": {}, "GR": {}, "GS": {}, "GT": {}, "GU": {}, "GW": {}, "GY": {}, "HK": {}, "HM": {},
+	"HN": {}, "HR": {}, "HT": {}, "HU": {}, "ID": {}, "IE": {}, "IL": {}, "IM": {}, "IN": {}, "IO": {}, "IQ": {}, "IR": {},
+	"IS": {}, "IT": {}, "JE": {}, "JM": {}, "JO": {}, "JP": {}, "KE": {}, "KG": {}, "KH": {}, "KI": {}, "KM": {}, "KN": {},
+	"KP": {}, "KR": {}, "KW": {}, "KY": {}, "KZ": {}, "LA": {}, "LB": {}, "LC": {}, "LI": {}, "LK": {}, "LR": {}, "LS": {},
+	"LT": {}, "LU": {}, "LV": {}, "LY": {}, "MA": {}, "MC": {}, "MD": {}, "ME": {}, "MF": {}, "MG": {}, "MH": {}, "MK": {},
+	"ML": {}, "MM": {}, "MN": {}, "MO": {}, "MP": {}, "MQ": {}, "MR": {}, "MS": {}, "MT": {}, "MU": {}, "MV": {}, "MW": {},
+	"MX": {}, "MY": {}, "MZ": {}, "NA": {}, "NC": {}, "NE": {}, "NF": {}, "NG": {}, "NI": {}, "NL": {}, "NO": {}, "NP": {},
+	"NR": {}, "NU": {}, "NZ": {}, "OM": {}, "PA": {}, "PE": {}, "PF": {}, "PG": {}, "PH": {}, "PK": {}, "PL": {}, "PM": {},
+	"PN": {}, "PR": {}, "PS": {}, "PT": {}, "PW": {}, "PY": {}, "QA": {}, "RE": {}, "RO": {}, "RS": {}, "RU": {}, "RW": {},
+	"SA": {}, "SB": {}, "SC": {}, "SD": {}, "SE": {}, "SG": {}, "SH": {}, "SI": {}, "SJ": {}, "SK": {}, "SL": {}, "SM": {},
+	"SN": {}, "SO": {}, "SR": {}, "SS": {}, "ST": {}, "SV": {}, "SX": {}, "SY": {}, "SZ": {}, "TC": {}, "TD": {}, "TF": {},
+	"TG": {}, "TH": {}, "TJ": {}, "TK": {}, "TL": {}, "TM": {}, "TN": {}, "TO": {}, "TR": {}, "TT": {}, "TV": {}, "TW": {},
+	"TZ": {}, "UA": {}, "UG": {}, "UM": {}, "US": {}, "UY": {}, "UZ": {}, "VA": {}, "VC": {}, "VE": {}, "VG": {}, "VI": {},
+	"VN": {}, "VU": {}, "WF": {}, "WS": {}, "YE": {}, "YT": {}, "ZA": {}, "ZM": {}, "ZW": {},
+}
+
+// currencyCodes holds the ISO 4217 alphabetic codes of the currencies and funds in use.
+var currencyCodes = map[string]struct{}{
+	"AED": {}, "AFN": {}, "ALL": {}, "AMD": {}, "ANG": {}, "AOA": {}, "ARS": {}, "AUD": {}, "AWG": {}, "AZN": {}, "BAM": {},
+	"BBD": {}, "BDT": {}, "BGN": {}, "BHD": {}, "BIF": {}, "BMD": {}, "BND": {}, "BOB": {}, "BOV": {}, "BRL": {}, "BSD": {},
+	"BTN": {}, "BWP": {}, "BYN": {}, "BZD": {}, "CAD": {}, "CDF": {}, "CHE": {}, "CHF": {}, "CHW": {}, "CLF": {}, "CLP": {},
+	"CNY": {}, "COP": {}, "COU": {}, "CRC": {}, "CUC": {}, "CUP": {}, "CVE": {}, "CZK": {}, "DJF": {}, "DKK": {}, "DOP": {},
+	"DZD": {}, "EGP": {}, "ERN": {}, "ETB": {}, "EUR": {}, "FJD": {}, "FKP": {}, "GBP": {}, "GEL": {}, "GHS": {}, "GIP": {},
+	"GMD": {}, "GNF": {}, "GTQ": {}, "GYD": {}, "HKD": {}, "HNL": {}, "HTG": {}, "HUF": {}, "IDR": {}, "ILS": {}, "INR": {},
+	"IQD": {}, "IRR": {}, "ISK": {}, "JMD": {}, "JOD": {}, "JPY": {}, "KES": {}, "KGS": {}, "KHR": {}, "KMF": {}, "KPW": {},
+	"KRW": {}, "KWD": {}, "KYD": {}, "KZT": {}, "LAK": {}, "LBP": {}, "LKR": {}, "LRD": {}, "LSL": {}, "LYD": {}, "MAD": {},
+	"MDL": {}, "MGA": {}, "MKD": {}, "MMK": {}, "MNT": {}, "MOP": {}, "MRU": {}, "MUR": {}, "MVR": {}, "MWK": {}, "MXN": {},
+	"MXV": {}, "MYR": {}, "MZN": {}, "NAD": {}, "NGN": {}, "NIO": {}, "NOK": {}, "NPR": {}, "NZD": {}, "OMR": {}, "PAB": {},
+	"PEN": {}, "PGK": {}, "PHP": {}, "PKR": {}, "PLN": {}, "PYG": {}, "QAR": {}, "RON": {}, "RSD": {}, "RUB": {}, "RWF": {},
+	"SAR": {}, "SBD": {}, "SCR": {}, "SDG": {}, "SEK": {}, "SGD": {}, "SHP": {}, "SLE": {}, "SLL": {}, "SOS": {}, "SRD": {},
+	"SSP": {}, "STN": {}, "SVC": {}, "SYP": {}, "SZL": {}, "THB": {}, "TJS": {}, "TMT": {}, "TND": {}, "TOP": {}, "TRY": {},
+	"TTD": {}, "TWD": {}, "TZS": {}, "UAH": {}, "UGX": {}, "USD": {}, "USN": {}, "UYI": {}, "UYU": {}, "UYW": {}, "UZS": {},
+	"VED": {}, "VES": {}, "VND": {}, "VUV": {}, "WST": {}, "XAF": {}, "XAG": {}, "XAU": {}, "XBA": {}, "XBB": {}, "XBC": {},
+	"XBD": {}, "XCD": {}, "XDR": {}, "XOF": {}, "XPD": {}, "XPF": {}, "XPT": {}, "XSU": {}, "XUA": {}, "YER": {}, "ZAR": {},
+	"ZMW": {}, "ZWL": {},
+}
+
+// bankIDLengths holds the number of digits of the national bank code, e.g. the sort code in GB or the BSB in AU,
+// of the countries whose bank codes are purely numeric and of a single fixed length.
+var bankIDLengths = map[string]int{
+	"AU": 6, "BE": 3, "CA": 9, "CH": 5, "DE": 8, "ES": 8, "GB": 6, "GR": 7, "HK": 3, "LU": 3, "PL": 8, "PT": 8, "US": 9,
+}
+
+var ninetySeven = big.NewInt(97)
+
+// ValidateIBAN checks that iban is well formed as per ISO 13616, i.e. that it is made of upper case letters
+// and digits only, has the length registered for its country and passes the mod-97 checksum.
+// Spaces, as found in the printed form of an IBAN, are ignored.
+func ValidateIBAN(iban string) error {
+	iban = strings.ReplaceAll(iban, " ", "")
+	if len(iban) < 4 {
+		return errors.New("iban is too short")
+	}
+	for _, c := range iban {
+		if !isUpperAlphanumeric(c) {
+			return fmt.Errorf("iban contains an invalid character %q", c)
+		}
+	}
+
+	country := iban[:2]
+	expectedLength, ok := ibanLengths[country]
+	if !ok {
+		return fmt.Errorf("iban country code %s is not in the iban registry", country)
+	}
+	if len(iban) != expectedLength {
+		return fmt.Errorf("iban for %s must be %d characters long, got %d", country, expectedLength, len(iban))
+	}
+
+	// the first four characters are moved to the end and every letter is replaced by two digits, A = 10 ... Z = 35
+	var digits strings.Builder
+	for _, c := range iban[4:] + iban[:4] {
+		if c >= 'A' && c <= 'Z' {
+			fmt.Fprintf(&digits, "%d", c-'A'+10)
+		} else {
+			digits.WriteRune(c)
+		}
+	}
+	n, _ := new(big.Int).SetString(digits.String(), 10)
+	if new(big.Int).Mod(n, ninetySeven).Int64() != 1 {
+		return errors.New("iban checksum mismatch")
+	}
+	return nil
+}
+
+// ValidateBIC checks that bic follows the ISO 9362 SWIFT format: a 4 letter bank code, a 2 letter country code,
+// a 2 character alphanumeric location code and an optional 3 character alphanumeric branch code, all upper case.
+func ValidateBIC(bic string) error {
+	if len(bic) != 8 && len(bic) != 11 {
+		return fmt.Errorf("bic must be 8 or 11 characters long, got %d", len(bic))
+	}
+	for i, c := range bic {
+		if i < 6 && !(c >= 'A' && c <= 'Z') {
+			return fmt.Errorf("bic bank and country codes must be upper case letters, got %q", c)
+		}
+		if !isUpperAlphanumeric(c) {
+			return fmt.Errorf("bic contains an invalid character %q", c)
+		}
+	}
+	return nil
+}
+
+// ValidateCountryCode checks that code is an ISO 3166-1 alpha-2 country code, e.g. "GB".
+// The check is case-sensitive, only the upper case form is accepted.
+func ValidateCountryCode(code string) error {
+	if _, ok := countryCodes[code]; !ok {
+		return fmt.Errorf("%q is not an assigned country code", code)
+	}
+	return nil
+}
+
+// ValidateCurrencyCode checks that code is an ISO 4217 alphabetic currency code, e.g. "GBP".
+// The check is case-sensitive, only the upper case form is accepted.
+func ValidateCurrencyCode(code string) error {
+	if _, ok := currencyCodes[code]; !ok {
+		return fmt.Errorf("%q is not an ISO 4217 currency code", code)
+	}
+	return nil
+}
+
+// ValidateBankID checks that bankID follows the national bank code format of the given country,
+// e.g. 6 digits for a GB sort code. Countries with no known format let any bank id through.
+func ValidateBankID(countryCode, bankID string) error {
+	length, ok := bankIDLengths[countryCode]
+	if !ok {
+		return nil
+	}
+	valid := len(bankID) == length
+	for i := 0; valid && i < len(bankID); i++ {
+		valid = bankID[i] >= '0' && bankID[i] <= '9'
+	}
+	if !valid {
+		return fmt.Errorf(msgInvalidBankID, countryCode, length)
+	}
+	return nil
+}
+
+// RequireFields makes sure the fields the accounts API requires of a new account are set, i.e. its ID,
+// OrganisationID and Type, reporting the first one missing by its json name, e.g. "missing required field: organisation_id".
+func (a *AccountData) RequireFields() *HTTPError {
+	if a == nil {
+		return &HTTPError{
+			Message: msgNilAccount,
+			Kind:    KindValidation,
+		}
+	}
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"id", a.ID},
+		{"organisation_id", a.OrganisationID},
+		{"type", a.Type},
+	}
+	for _, field := range required {
+		if field.value == "" {
+			return &HTTPError{
+				Message: fmt.Sprintf(msgMissingField, field.name),
+				Kind:    KindValidation,
+			}
+		}
+	}
+	return nil
+}
+
+// validateAccount performs the client side checks enabled by WithClientSideValidation.
+// Beyond the required fields, only the attributes that are actually set get validated.
+func validateAccount(account *AccountData) *HTTPError {
+	if httpErr := account.RequireFields(); httpErr != nil {
+		return httpErr
+	}
+	if account.Attributes == nil {
+		return nil
+	}
+	if classification, ok := account.Attributes.AccountClassificationValue(); ok && !AccountClassification(classification).Valid() {
+		return &HTTPError{
+			Message: fmt.Sprintf(msgInvalidClassification, classification),
+			Kind:    KindValidation,
+		}
+	}
+	if iban := account.Attributes.Iban; iban != "" {
+		if err := ValidateIBAN(iban); err != nil {
+			return &HTTPError{
+				Cause:   err,
+				Message: msgInvalidIban,
+				Kind:    KindValidation,
+			}
+		}
+	}
+	if bic := account.Attributes.Bic; bic != "" {
+		if err := ValidateBIC(bic); err != nil {
+			return &HTTPError{
+				Cause:   err,
+				Message: msgInvalidBic,
+				Kind:    KindValidation,
+			}
+		}
+	}
+	if country := account.Attributes.Country; country != nil {
+		if err := ValidateCountryCode(*country); err != nil {
+			return &HTTPError{
+				Cause:   err,
+				Message: msgInvalidCountry,
+				Kind:    KindValidation,
+			}
+		}
+		if bankID := account.Attributes.BankID; bankID != "" {
+			if err := ValidateBankID(*country, bankID); err != nil {
+				return &HTTPError{
+					Message: err.Error(),
+					Kind:    KindValidation,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func isUpperAlphanumeric(c rune) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,379 @@
+package interview_accountapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateIBAN(t *testing.T) {
+	tests := []struct {
+		name  string
+		iban  string
+		valid bool
+	}{
+		{"GB", "GB82WEST12345698765432", true},
+		{"DE", "DE89370400440532013000", true},
+		{"FR", "FR1420041010050500013M02606", true},
+		{"NL", "NL91ABNA0417164300", true},
+		{"NO", "NO9386011117947", true},
+		{"printed form with spaces", "GB82 WEST 1234 5698 7654 32", true},
+		{"corrupted digit", "GB82WEST12345698765433", false},
+		{"swapped digits", "GB82WEST12345698765423", false},
+		{"wrong check digits", "DE88370400440532013000", false},
+		{"too long for country", "GB82WEST123456987654321", false},
+		{"too short for country", "NL91ABNA041716430", false},
+		{"unknown country", "ZZ82WEST12345698765432", false},
+		{"lower case", "gb82west12345698765432", false},
+		{"invalid character", "GB82-WEST12345698765432", false},
+		{"too short", "GB8", false},
+		{"empty", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateIBAN(test.iban)
+			if test.valid && err != nil {
+				t.Errorf("Expecting %s to be valid, got=%v", test.iban, err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("Expecting %s to be rejected", test.iban)
+			}
+		})
+	}
+}
+
+func TestCreate_ClientSideValidation_InvalidIban(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithClientSideValidation())
+	account, httpErr := client.Create(context.Background(), &AccountData{
+		ID:             "0d209d7f-d07a-4542-947f-5885fddddae2",
+		OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43",
+		Type:           "accounts",
+		Attributes:     &AccountAttributes{Iban: "GB82WEST12345698765433"},
+	})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:   ValidateIBAN("GB82WEST12345698765433"),
+		Message: "iban failed checksum validation",
+		Kind:    KindValidation,
+	})
+	assertAccountData(t, account, nil)
+	if requests != 0 {
+		t.Errorf("Expecting the request not to be sent, got %d requests", requests)
+	}
+}
+
+func TestCreate_ClientSideValidation_ValidIban(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":{"id":"0d209d7f-d07a-4542-947f-5885fddddae2","attributes":{"iban":"GB82WEST12345698765432"}}}`))
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithClientSideValidation())
+	_, httpErr := client.Create(context.Background(), &AccountData{
+		ID:             "0d209d7f-d07a-4542-947f-5885fddddae2",
+		OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43",
+		Type:           "accounts",
+		Attributes:     &AccountAttributes{Iban: "GB82WEST12345698765432"},
+	})
+
+	assertHttpError(t, httpErr, nil)
+	if requests != 1 {
+		t.Errorf("Expecting a single request, got=%d", requests)
+	}
+}
+
+func TestCreate_InvalidIbanSentWithoutClientSideValidation(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL)
+	client.Create(context.Background(), &AccountData{
+		Attributes: &AccountAttributes{Iban: "GB82WEST12345698765433"},
+	})
+
+	if requests != 1 {
+		t.Errorf("Expecting the request to be sent, got %d requests", requests)
+	}
+}
+
+func TestValidateBIC(t *testing.T) {
+	tests := []struct {
+		name  string
+		bic   string
+		valid bool
+	}{
+		{"8 characters", "DEUTDEFF", true},
+		{"11 characters", "DEUTDEFF500", true},
+		{"digits in location", "NWBKGB2L", true},
+		{"primary office branch", "NWBKGB2LXXX", true},
+		{"lower case", "deutdeff", false},
+		{"lower case branch", "DEUTDEFFabc", false},
+		{"digit in bank code", "DEU1DEFF", false},
+		{"digit in country code", "DEUTD3FF", false},
+		{"too short", "DEUTDEF", false},
+		{"9 characters", "DEUTDEFF5", false},
+		{"too long", "DEUTDEFF5000", false},
+		{"invalid character", "DEUTDE-F", false},
+		{"empty", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateBIC(test.bic)
+			if test.valid && err != nil {
+				t.Errorf("Expecting %s to be valid, got=%v", test.bic, err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("Expecting %s to be rejected", test.bic)
+			}
+		})
+	}
+}
+
+func TestCreate_ClientSideValidation_InvalidBic(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithClientSideValidation())
+	account, httpErr := client.Create(context.Background(), &AccountData{
+		ID:             "0d209d7f-d07a-4542-947f-5885fddddae2",
+		OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43",
+		Type:           "accounts",
+		Attributes:     &AccountAttributes{Bic: "deutdeff"},
+	})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:   ValidateBIC("deutdeff"),
+		Message: "bic is not a valid SWIFT code",
+		Kind:    KindValidation,
+	})
+	assertAccountData(t, account, nil)
+	if requests != 0 {
+		t.Errorf("Expecting the request not to be sent, got %d requests", requests)
+	}
+}
+
+func TestValidateCountryCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		code  string
+		valid bool
+	}{
+		{"CA", "CA", true},
+		{"GB", "GB", true},
+		{"US", "US", true},
+		{"lower case", "ca", false},
+		{"mixed case", "Ca", false},
+		{"full name", "Canada", false},
+		{"alpha-3", "CAN", false},
+		{"unassigned", "ZZ", false},
+		{"empty", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateCountryCode(test.code)
+			if test.valid && err != nil {
+				t.Errorf("Expecting %s to be valid, got=%v", test.code, err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("Expecting %s to be rejected", test.code)
+			}
+		})
+	}
+}
+
+func TestValidateCurrencyCode(t *testing.T) {
+	for _, code := range []string{"GBP", "EUR", "USD", "JPY"} {
+		if err := ValidateCurrencyCode(code); err != nil {
+			t.Errorf("Expecting %s to be valid, got=%v", code, err)
+		}
+	}
+	for _, code := range []string{"gbp", "GB", "GBPP", "ZZZ", ""} {
+		if err := ValidateCurrencyCode(code); err == nil {
+			t.Errorf("Expecting %s to be rejected", code)
+		}
+	}
+}
+
+func TestCreate_ClientSideValidation_InvalidCountry(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithClientSideValidation())
+	country := "Canada"
+	account, httpErr := client.Create(context.Background(), &AccountData{
+		ID:             "0d209d7f-d07a-4542-947f-5885fddddae2",
+		OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43",
+		Type:           "accounts",
+		Attributes:     &AccountAttributes{Country: &country},
+	})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:   ValidateCountryCode(country),
+		Message: "country must be an ISO 3166-1 alpha-2 code",
+		Kind:    KindValidation,
+	})
+	assertAccountData(t, account, nil)
+	if requests != 0 {
+		t.Errorf("Expecting the request not to be sent, got %d requests", requests)
+	}
+}
+
+func TestValidateBankID(t *testing.T) {
+	tests := []struct {
+		name     string
+		country  string
+		bankID   string
+		expected string
+	}{
+		{"GB sort code", "GB", "400300", ""},
+		{"GB too short", "GB", "40030", "bank_id for GB must be 6 digits"},
+		{"GB too long", "GB", "4003001", "bank_id for GB must be 6 digits"},
+		{"GB not numeric", "GB", "40030A", "bank_id for GB must be 6 digits"},
+		{"AU bsb", "AU", "062000", ""},
+		{"AU with dash", "AU", "062-000", "bank_id for AU must be 6 digits"},
+		{"unknown country", "FR", "20041010", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateBankID(test.country, test.bankID)
+			if test.expected == "" && err != nil {
+				t.Errorf("Expecting %s to be valid, got=%v", test.bankID, err)
+			}
+			if test.expected != "" && (err == nil || err.Error() != test.expected) {
+				t.Errorf("Expecting error=%s, got=%v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestCreate_ClientSideValidation_InvalidBankID(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithClientSideValidation())
+	country := "GB"
+	account, httpErr := client.Create(context.Background(), &AccountData{
+		ID:             "0d209d7f-d07a-4542-947f-5885fddddae2",
+		OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43",
+		Type:           "accounts",
+		Attributes:     &AccountAttributes{Country: &country, BankID: "4003"},
+	})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "bank_id for GB must be 6 digits",
+		Kind:    KindValidation,
+	})
+	assertAccountData(t, account, nil)
+	if requests != 0 {
+		t.Errorf("Expecting the request not to be sent, got %d requests", requests)
+	}
+}
+
+func TestRequireFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		account *AccountData
+		message string
+	}{
+		{"all set", &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2", OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43", Type: "accounts"}, ""},
+		{"missing id", &AccountData{OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43", Type: "accounts"}, "missing required field: id"},
+		{"missing organisation id", &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2", Type: "accounts"}, "missing required field: organisation_id"},
+		{"missing type", &AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2", OrganisationID: "ba61483c-d5c5-4f50-ae81-6b8c039bea43"}, "missing required field: type"},
+		{"nothing set", &AccountData{}, "missing required field: id"},
+		{"nil", nil, "account must not be nil"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var expected *HTTPError
+			if test.message != "" {
+				expected = &HTTPError{Message: test.message, Kind: KindValidation}
+			}
+			assertHttpError(t, test.account.RequireFields(), expected)
+		})
+	}
+}
+
+func TestCreate_ClientSideValidation_MissingRequiredField(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithClientSideValidation())
+	account, httpErr := client.Create(context.Background(), &AccountData{
+		ID:   "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Type: "accounts",
+	})
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "missing required field: organisation_id",
+		Kind:    KindValidation,
+	})
+	assertAccountData(t, account, nil)
+	if requests != 0 {
+		t.Errorf("Expecting the request not to be sent, got %d requests", requests)
+	}
+}
+
+func TestCreate_ClientSideValidation_InvalidClassification(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithClientSideValidation())
+	account, httpErr := client.Create(context.Background(), NewAccountDataBuilder().
+		WithOrganisationID("ba61483c-d5c5-4f50-ae81-6b8c039bea43").
+		WithCountry("GB").
+		WithClassification("Unexpected Classification").
+		Build())
+
+	assertHttpError(t, httpErr, &HTTPError{
+		Message: "account_classification must be Personal or Business, got Unexpected Classification",
+		Kind:    KindValidation,
+	})
+	assertAccountData(t, account, nil)
+	if requests != 0 {
+		t.Errorf("Expecting the request not to be sent, got %d requests", requests)
+	}
+}
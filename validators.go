@@ -0,0 +1,112 @@
+package interview_accountapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AccountValidator checks an account the client is about to send, see WithValidators.
+type AccountValidator func(*AccountData) error
+
+// WithValidators runs the given validators, in order, on the account of every Create and on the patch of every Update,
+// the latter carrying the id and version it is sent with. The first validator to fail stops the chain and the request
+// is rejected with a KindValidation error caused by what the validator returned, without a round trip to the server.
+// Validators can be picked from the built-in ones, e.g. ValidateAccountIBAN, and mixed with custom ones.
+// Options given more than once add to the chain.
+func WithValidators(validators ...AccountValidator) ClientOption {
+	return func(hac *httpAccountsClientImpl) error {
+		for _, validator := range validators {
+			if validator == nil {
+				return errors.New("validators must not be nil")
+			}
+		}
+		hac.validators = append(hac.validators, validators...)
+		return nil
+	}
+}
+
+// runValidators runs the validators given in WithValidators on account, stopping at the first one failing.
+func (hac *httpAccountsClientImpl) runValidators(account *AccountData) *HTTPError {
+	if len(hac.validators) == 0 {
+		return nil
+	}
+	if account == nil {
+		return &HTTPError{
+			Message: msgNilAccount,
+			Kind:    KindValidation,
+		}
+	}
+	for _, validate := range hac.validators {
+		if err := validate(account); err != nil {
+			return &HTTPError{
+				Cause:   err,
+				Message: fmt.Sprintf(msgValidatorFailed, err),
+				Kind:    KindValidation,
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateAccountID checks that the id of the account is a uuid.
+func ValidateAccountID(a *AccountData) error {
+	if a == nil || !isValidUUID(a.ID) {
+		return errors.New(msgInvalidID)
+	}
+	return nil
+}
+
+// ValidateAccountClassification checks that the account classification, if set, is Personal or Business.
+func ValidateAccountClassification(a *AccountData) error {
+	if classification, ok := attributesOf(a).AccountClassificationValue(); ok && !AccountClassification(classification).Valid() {
+		return fmt.Errorf(msgInvalidClassification, classification)
+	}
+	return nil
+}
+
+// ValidateAccountCountry checks that the country, if set, is an ISO 3166-1 alpha-2 code, see ValidateCountryCode,
+// and that the bank id, if set as well, follows the format of that country, see ValidateBankID.
+func ValidateAccountCountry(a *AccountData) error {
+	attributes := attributesOf(a)
+	if attributes == nil || attributes.Country == nil {
+		return nil
+	}
+	if err := ValidateCountryCode(*attributes.Country); err != nil {
+		return err
+	}
+	if attributes.BankID != "" {
+		return ValidateBankID(*attributes.Country, attributes.BankID)
+	}
+	return nil
+}
+
+// ValidateAccountCurrency checks that the base currency, if set, is an ISO 4217 code, see ValidateCurrencyCode.
+func ValidateAccountCurrency(a *AccountData) error {
+	if attributes := attributesOf(a); attributes != nil && attributes.BaseCurrency != "" {
+		return ValidateCurrencyCode(attributes.BaseCurrency)
+	}
+	return nil
+}
+
+// ValidateAccountIBAN checks that the IBAN, if set, is well formed, see ValidateIBAN.
+func ValidateAccountIBAN(a *AccountData) error {
+	if attributes := attributesOf(a); attributes != nil && attributes.Iban != "" {
+		return ValidateIBAN(attributes.Iban)
+	}
+	return nil
+}
+
+// ValidateAccountBIC checks that the BIC, if set, follows the SWIFT format, see ValidateBIC.
+func ValidateAccountBIC(a *AccountData) error {
+	if attributes := attributesOf(a); attributes != nil && attributes.Bic != "" {
+		return ValidateBIC(attributes.Bic)
+	}
+	return nil
+}
+
+func attributesOf(a *AccountData) *AccountAttributes {
+	if a == nil {
+		return nil
+	}
+	return a.Attributes
+}
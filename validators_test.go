@@ -0,0 +1,151 @@
+package interview_accountapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestWithValidators_RunInOrderAndShortCircuit(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	var calls []string
+	recording := func(name string, err error) AccountValidator {
+		return func(*AccountData) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+	errCustom := errors.New("organisation is not onboarded")
+
+	tests := []struct {
+		name          string
+		validators    []AccountValidator
+		iban          string
+		expectedCalls []string
+		expectedCause error
+	}{
+		{"all pass", []AccountValidator{ValidateAccountID, recording("first", nil), ValidateAccountIBAN, recording("second", nil)},
+			"GB82WEST12345698765432", []string{"first", "second"}, nil},
+		{"built-in fails", []AccountValidator{ValidateAccountID, recording("first", nil), ValidateAccountIBAN, recording("second", nil)},
+			"GB82WEST12345698765433", []string{"first"}, ValidateIBAN("GB82WEST12345698765433")},
+		{"custom fails", []AccountValidator{recording("first", errCustom), ValidateAccountIBAN, recording("second", nil)},
+			"GB82WEST12345698765433", []string{"first"}, errCustom},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls = nil
+			posts := server.count(http.MethodPost)
+			clientFactory := AccountsHttpClientFactory{}
+			client, _ := clientFactory.MakeClient(server.URL, WithValidators(test.validators...))
+			_, httpErr := client.Create(context.Background(), &AccountData{
+				ID:         "0d209d7f-d07a-4542-947f-5885fddddae2",
+				Attributes: &AccountAttributes{Iban: test.iban},
+			})
+
+			if test.expectedCause == nil {
+				assertHttpError(t, httpErr, nil)
+			} else {
+				assertHttpError(t, httpErr, &HTTPError{
+					Cause:   test.expectedCause,
+					Message: "account failed validation: " + test.expectedCause.Error(),
+					Kind:    KindValidation,
+				})
+				if httpErr.Cause.Error() != test.expectedCause.Error() {
+					t.Errorf("Expecting cause=%v, got=%v", test.expectedCause, httpErr.Cause)
+				}
+				if server.count(http.MethodPost) != posts {
+					t.Errorf("Expecting the request not to be sent")
+				}
+			}
+			if len(calls) != len(test.expectedCalls) {
+				t.Fatalf("Expecting calls=%v, got=%v", test.expectedCalls, calls)
+			}
+			for i := range calls {
+				if calls[i] != test.expectedCalls[i] {
+					t.Errorf("Expecting calls=%v, got=%v", test.expectedCalls, calls)
+				}
+			}
+		})
+	}
+}
+
+func TestWithValidators_Update(t *testing.T) {
+	server := newAccountServer(0)
+	defer server.Close()
+
+	var validated *AccountData
+	clientFactory := AccountsHttpClientFactory{}
+	client, _ := clientFactory.MakeClient(server.URL, WithValidators(ValidateAccountID, ValidateAccountCurrency,
+		func(a *AccountData) error {
+			validated = a
+			return nil
+		}))
+
+	// the patch is validated as sent, i.e. with the id and version it goes with
+	_, httpErr := client.Update(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 3,
+		&AccountData{Attributes: &AccountAttributes{BaseCurrency: "GBP"}})
+	assertHttpError(t, httpErr, nil)
+	if validated == nil || validated.ID != "0d209d7f-d07a-4542-947f-5885fddddae2" || *validated.Version != 3 {
+		t.Errorf("Expecting the patch to be validated, got=%v", validated)
+	}
+
+	_, httpErr = client.Update(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2", 3,
+		&AccountData{Attributes: &AccountAttributes{BaseCurrency: "GBX"}})
+	assertHttpError(t, httpErr, &HTTPError{
+		Cause:   ValidateCurrencyCode("GBX"),
+		Message: `account failed validation: "GBX" is not an ISO 4217 currency code`,
+		Kind:    KindValidation,
+	})
+	if server.count(http.MethodPatch) != 1 {
+		t.Errorf("Expecting only the valid patch to be sent, got %d", server.count(http.MethodPatch))
+	}
+}
+
+func TestWithValidators_Invalid(t *testing.T) {
+	clientFactory := AccountsHttpClientFactory{}
+	_, err := clientFactory.MakeClient("http://localhost:8080", WithValidators(ValidateAccountID, nil))
+
+	if err == nil || err.Error() != "validators must not be nil" {
+		t.Errorf("Expecting validators validation error, got=%v", err)
+	}
+}
+
+func TestBuiltInAccountValidators(t *testing.T) {
+	country, classification := "GB", "Corporate"
+	tests := []struct {
+		name      string
+		validator AccountValidator
+		valid     *AccountData
+		invalid   *AccountData
+	}{
+		{"id", ValidateAccountID,
+			&AccountData{ID: "0d209d7f-d07a-4542-947f-5885fddddae2"}, &AccountData{ID: "blah"}},
+		{"classification", ValidateAccountClassification,
+			&AccountData{}, &AccountData{Attributes: &AccountAttributes{AccountClassification: &classification}}},
+		{"country", ValidateAccountCountry,
+			&AccountData{Attributes: &AccountAttributes{Country: &country, BankID: "400300"}},
+			&AccountData{Attributes: &AccountAttributes{Country: &country, BankID: "4003"}}},
+		{"currency", ValidateAccountCurrency,
+			&AccountData{Attributes: &AccountAttributes{BaseCurrency: "EUR"}},
+			&AccountData{Attributes: &AccountAttributes{BaseCurrency: "eur"}}},
+		{"iban", ValidateAccountIBAN,
+			&AccountData{Attributes: &AccountAttributes{Iban: "GB82WEST12345698765432"}},
+			&AccountData{Attributes: &AccountAttributes{Iban: "GB82WEST"}}},
+		{"bic", ValidateAccountBIC,
+			&AccountData{Attributes: &AccountAttributes{Bic: "NWBKGB22"}},
+			&AccountData{Attributes: &AccountAttributes{Bic: "nwbkgb22"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.validator(test.valid); err != nil {
+				t.Errorf("Expecting %v to be valid, got=%v", test.valid, err)
+			}
+			if err := test.validator(test.invalid); err == nil {
+				t.Errorf("Expecting %v to be rejected", test.invalid)
+			}
+		})
+	}
+}